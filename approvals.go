@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fcoury/rtc-go/models"
+	"github.com/gistia/tablewriter"
+)
+
+func formatDueDate(due string) string {
+	if due == "" {
+		return "-"
+	}
+
+	if t, err := time.Parse(time.RFC3339, due); err == nil {
+		return t.Local().Format("2006-01-02")
+	}
+
+	return due
+}
+
+func approvers(a models.Approval) string {
+	names := []string{}
+	for _, aa := range a.Approvals {
+		for _, ap := range aa.Approvers {
+			names = append(names, fmt.Sprintf("%s (%s)", ap.Name, aa.State()))
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func renderApprovals(title string, as []models.Approval) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Id", "Type", "Name", "State", "Due", "Approvers"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetColWidth(appConfig.MaxWidth)
+
+	for _, a := range as {
+		table.Append([]string{a.Id, a.Type(), a.Name, a.State(), formatDueDate(a.DueDate), approvers(a)})
+	}
+
+	fmt.Println(title)
+	fmt.Println("")
+	table.Render()
+}
+
+func listApprovals(id string) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	as, err := r.ListApprovals(id)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if len(as) < 1 {
+		fmt.Println("No approvals to be displayed.")
+		return
+	}
+
+	renderApprovals("Approvals of work item "+id, as)
+}
+
+func setApprovalState(id string, approvalId string, state string) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	fmt.Printf("Setting approval %s of work item %s to %s...\n", approvalId, id, state)
+	err = r.SetApprovalState(id, approvalId, state)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	fmt.Println("Approval successfully updated")
+}
+
+func removeApproval(id string, approvalId string) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	fmt.Printf("Removing approval %s from work item %s...\n", approvalId, id)
+	err = r.RemoveApproval(id, approvalId)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	fmt.Println("Approval successfully removed")
+}
+
+func pendingApprovals() {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	fmt.Println("Looking for approvals waiting for you...")
+	pending, err := r.PendingApprovals()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if len(pending) < 1 {
+		fmt.Println("No approvals waiting for you.")
+		return
+	}
+
+	fmt.Println("")
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Work Item", "Summary", "Approval", "Type", "Name", "Due"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetColWidth(appConfig.MaxWidth)
+
+	for _, p := range pending {
+		a := p.Approval
+		table.Append([]string{p.WorkItem.Id, p.WorkItem.Summary, a.Id, a.Type(), a.Name, formatDueDate(a.DueDate)})
+	}
+	table.Render()
+}
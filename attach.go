@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gistia/tablewriter"
+)
+
+func formatSize(size int64) string {
+	switch {
+	case size >= 1024*1024:
+		return strconv.FormatFloat(float64(size)/(1024*1024), 'f', 1, 64) + " MB"
+	case size >= 1024:
+		return strconv.FormatFloat(float64(size)/1024, 'f', 1, 64) + " KB"
+	}
+
+	return strconv.FormatInt(size, 10) + " B"
+}
+
+func listAttachments(id string) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	atts, err := r.ListAttachments(id)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	if len(atts) < 1 {
+		fmt.Println("No attachments to be displayed.")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Id", "Name", "Size", "Created", "Author"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetColWidth(appConfig.MaxWidth)
+
+	for _, a := range atts {
+		table.Append([]string{a.Id, a.Name, formatSize(a.Size), a.Created.Local().Format("2006-01-02 15:04"), a.Author})
+	}
+	table.Render()
+}
+
+func getAttachment(id string, attachmentId string, out string) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	if out == "" {
+		atts, err := r.ListAttachments(id)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		for _, a := range atts {
+			if a.Id == attachmentId {
+				out = filepath.Base(a.Name)
+			}
+		}
+
+		if out == "" {
+			fmt.Println("No attachment " + attachmentId + " on work item " + id + ". Use attach ls.")
+			os.Exit(1)
+		}
+	}
+
+	if out == "-" {
+		err = r.DownloadAttachment(attachmentId, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	// download next to the destination and only replace it once complete
+	f, err := ioutil.TempFile(filepath.Dir(out), "."+filepath.Base(out)+".")
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("Downloading attachment %s to %s...\n", attachmentId, out)
+	err = r.DownloadAttachment(attachmentId, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	// temp files are private, downloads shouldn't be
+	if err == nil {
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), out)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Println("Attachment successfully downloaded")
+}
+
+func putAttachments(id string, files []string) {
+	if len(files) < 1 {
+		fmt.Println("Missing files to attach")
+		os.Exit(1)
+	}
+
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Printf("Attaching %s to work item %s...\n", file, id)
+		a, err := r.UploadAttachment(id, file, f)
+		f.Close()
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Printf("Attached %s as attachment %s\n", a.Name, a.Id)
+	}
+}
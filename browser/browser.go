@@ -3,13 +3,17 @@ package browser
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"net/http"
+	"sync"
 )
 
 type Browser struct {
 	Cookies      []*http.Cookie
 	LastResponse http.Response
 	Debug        bool
+
+	mutex sync.Mutex
 }
 
 func NewBrowser(debug bool) *Browser {
@@ -17,18 +21,22 @@ func NewBrowser(debug bool) *Browser {
 }
 
 func (b *Browser) Request(method string, url string, data string) (*http.Response, error) {
-	dataBuffer := bytes.NewBufferString(data)
+	return b.RequestBody(method, url, "application/x-www-form-urlencoded; charset=UTF-8", bytes.NewBufferString(data))
+}
 
-	r, err := http.NewRequest(method, url, dataBuffer)
+func (b *Browser) RequestBody(method string, url string, contentType string, body io.Reader) (*http.Response, error) {
+	r, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
-	r.Header.Add("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	r.Header.Add("Content-Type", contentType)
 
+	b.mutex.Lock()
 	for _, c := range b.Cookies {
 		r.AddCookie(c)
 	}
+	b.mutex.Unlock()
 
 	if err != nil {
 		return nil, err
@@ -52,9 +60,11 @@ func (b *Browser) Request(method string, url string, data string) (*http.Respons
 		return resp, err
 	}
 
+	b.mutex.Lock()
 	for _, c := range resp.Cookies() {
 		b.Cookies = append(b.Cookies, c)
 	}
+	b.mutex.Unlock()
 
 	return resp, err
 }
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/fcoury/rtc-go/rtc"
+	"github.com/gistia/tablewriter"
+)
+
+var fromQueryFlag = cli.StringFlag{
+	Name:  "from-query",
+	Value: "",
+	Usage: "Runs on the work items of a query, as in --from-query \"--mine --open\"",
+}
+
+// specFlags reads the query flags parsed from a --from-query spec.
+type specFlags struct {
+	set *flag.FlagSet
+}
+
+func (f specFlags) String(name string) string {
+	if fl := f.set.Lookup(name); fl != nil {
+		return fl.Value.String()
+	}
+
+	return ""
+}
+
+func (f specFlags) Bool(name string) bool {
+	b, _ := strconv.ParseBool(f.String(name))
+	return b
+}
+
+func (f specFlags) Int(name string) int {
+	i, _ := strconv.Atoi(f.String(name))
+	return i
+}
+
+// splitArgs splits a command line into words, keeping quoted words together.
+func splitArgs(s string) ([]string, error) {
+	args := []string{}
+	word := ""
+	inWord := false
+	var quote rune
+
+	for _, c := range s {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			word += string(c)
+		case c == '"' || c == '\'':
+			quote = c
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				args = append(args, word)
+			}
+			word = ""
+			inWord = false
+		default:
+			word += string(c)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("Unterminated quote in " + s)
+	}
+
+	if inWord {
+		args = append(args, word)
+	}
+
+	return args, nil
+}
+
+// parseQuery parses a query spec written with the same flags as the query
+// command. Unless the spec sets --maxresults the query returns every match,
+// as bulk commands must not skip any.
+func parseQuery(spec string) (Query, error) {
+	args, err := splitArgs(spec)
+	if err != nil {
+		return Query{}, err
+	}
+
+	set := flag.NewFlagSet("query", flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+	for _, f := range queryFlags {
+		f.Apply(set)
+	}
+
+	if err := set.Parse(args); err != nil {
+		return Query{}, errors.New("Invalid query " + spec + ": " + err.Error())
+	}
+
+	q := newQuery(specFlags{set})
+
+	limited := false
+	set.Visit(func(f *flag.Flag) {
+		if f.Name == "maxresults" {
+			limited = true
+		}
+	})
+	if !limited {
+		q.MaxResults = 0
+	}
+
+	return q, nil
+}
+
+// readIds reads work item ids separated by commas or white space.
+func readIds(r io.Reader) ([]string, error) {
+	ids := []string{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		ids = append(ids, splitIds(scanner.Text())...)
+	}
+
+	return ids, scanner.Err()
+}
+
+func splitIds(s string) []string {
+	return strings.FieldsFunc(s, func(c rune) bool {
+		return c == ',' || c == ' ' || c == '\t'
+	})
+}
+
+// targetIds returns the work items a bulk command runs on, along with the
+// rest of its arguments. Ids come from the query given with --from-query,
+// from stdin when the ids argument is "-", or from the first argument as a
+// comma separated list. When idsOnly is set every argument is taken as ids.
+func targetIds(r *rtc.RTC, c *cli.Context, idsOnly bool) ([]string, []string, error) {
+	args := []string(c.Args())
+
+	if spec := c.String("from-query"); spec != "" {
+		q, err := parseQuery(spec)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		wis, err := runQuery(r, q)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		ids := []string{}
+		for _, wi := range wis {
+			ids = append(ids, wi.Id)
+		}
+
+		return ids, args, nil
+	}
+
+	if len(args) < 1 {
+		return nil, nil, errors.New("Missing the work item ids. Use a comma separated list, - to read them from stdin or --from-query.")
+	}
+
+	if args[0] == "-" {
+		ids, err := readIds(os.Stdin)
+		return ids, args[1:], err
+	}
+
+	if idsOnly {
+		ids := []string{}
+		for _, a := range args {
+			ids = append(ids, splitIds(a)...)
+		}
+		return ids, nil, nil
+	}
+
+	return splitIds(args[0]), args[1:], nil
+}
+
+// runBulk runs fn on every work item, then prints a summary of what succeeded
+// and what failed, exiting with an error status if anything failed.
+func runBulk(r *rtc.RTC, verb string, ids []string, fn func(id string) (string, error)) {
+	if len(ids) < 1 {
+		fmt.Println("No work items to process.")
+		return
+	}
+
+	fmt.Printf("%s %d work item(s)...\n\n", verb, len(ids))
+	results := r.Bulk(ids, fn)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Id", "Result", "Details"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetColWidth(appConfig.MaxWidth)
+
+	for _, res := range results {
+		if res.Err != nil {
+			table.Append([]string{res.Id, "FAILED", res.Err.Error()})
+		} else {
+			table.Append([]string{res.Id, "OK", res.Message})
+		}
+	}
+	table.Render()
+
+	failed := rtc.Failed(results)
+	fmt.Printf("\n%d succeeded, %d failed.\n", len(results)-failed, failed)
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func describeChanges(changes []rtc.Change) string {
+	s := []string{}
+	for _, c := range changes {
+		s = append(s, fmt.Sprintf("%s: %s -> %s", c.Field, valueOrNone(c.Old), valueOrNone(c.New)))
+	}
+
+	if len(s) < 1 {
+		return "Nothing changed"
+	}
+
+	return strings.Join(s, "; ")
+}
@@ -17,6 +17,33 @@ type Config struct {
 	Pass     string `json:"pass"`
 	OwnerId  string `json:"rtcOwnerId"`
 	MaxWidth int
+
+	// Defaults are the attribute values, by attribute id, set on new work
+	// items of a type, or of any type with "*", when they aren't given.
+	Defaults map[string]map[string]string `json:"defaults,omitempty"`
+
+	// Templates are named sets of subtasks, see the subtask command.
+	Templates map[string][]rtc.SubtaskTemplate `json:"templates,omitempty"`
+
+	// Capacity is the work, like "60h" or "8d", each owner can take in an
+	// iteration, by owner name or id, or for everyone else with "*".
+	Capacity map[string]string `json:"capacity,omitempty"`
+}
+
+// defaultDefaults are the values the project area requires on new work items.
+var defaultDefaults = map[string]map[string]string{
+	"*": {"work_product_where_found": "Work_Product_where_found.literal.l2"},
+}
+
+// defaultTemplates are the subtasks of our definition of done for stories.
+var defaultTemplates = map[string][]rtc.SubtaskTemplate{
+	"story-dod": {
+		{Type: "Analysis"},
+		{Type: "Development"},
+		{Type: "Code Review"},
+		{Type: "Testing"},
+		{Type: "Artifacts"},
+	},
 }
 
 func ReadConfig() (*Config, error) {
@@ -53,6 +80,15 @@ func ReadConfig() (*Config, error) {
 	c.Pass = Decrypt(c.User, c.Pass)
 	c.MaxWidth = width
 
+	// configs written before defaults and templates existed still need the
+	// required values and the standard templates
+	if c.Defaults == nil {
+		c.Defaults = defaultDefaults
+	}
+	if c.Templates == nil {
+		c.Templates = defaultTemplates
+	}
+
 	return c, nil
 }
 
@@ -123,9 +159,25 @@ func CreateConfig() error {
 	ownerId := owners[owner-1]
 
 	c := &Config{
-		User:    user,
-		Pass:    Encrypt(user, pass),
-		OwnerId: ownerId,
+		User:      user,
+		Pass:      Encrypt(user, pass),
+		OwnerId:   ownerId,
+		Defaults:  defaultDefaults,
+		Templates: defaultTemplates,
+	}
+
+	// keep the defaults, templates and capacity of the previous config, if any
+	if data, err := ioutil.ReadFile(file); err == nil {
+		var old Config
+		if json.Unmarshal(data, &old) == nil {
+			if old.Defaults != nil {
+				c.Defaults = old.Defaults
+			}
+			if old.Templates != nil {
+				c.Templates = old.Templates
+			}
+			c.Capacity = old.Capacity
+		}
 	}
 
 	json, err := json.Marshal(c)
@@ -21,6 +21,24 @@ func configFile() (string, error) {
 	return filepath.Join(dir, ".rtcconfig"), nil
 }
 
+func journalFile() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, ".rtcjournal"), nil
+}
+
+func timerFile() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, ".rtctimer"), nil
+}
+
 func configDir() (string, error) {
 	// First prefer the HOME environmental variable
 	if home := os.Getenv("HOME"); home != "" {
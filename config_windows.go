@@ -24,6 +24,24 @@ func configFile() (string, error) {
 	return filepath.Join(dir, "rtc.config"), nil
 }
 
+func journalFile() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "rtc.journal"), nil
+}
+
+func timerFile() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "rtc.timer"), nil
+}
+
 func configDir() (string, error) {
 	b := make([]uint16, syscall.MAX_PATH)
 
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fcoury/rtc-go/rtc"
+)
+
+// fit cuts the line to the terminal width.
+func fit(line string) string {
+	width := appConfig.MaxWidth
+	if width < 20 || utf8.RuneCountInString(line) <= width {
+		return line
+	}
+
+	return string([]rune(line)[:width-3]) + "..."
+}
+
+func dashboardSection(title string, lines []string, more bool) {
+	count := strconv.Itoa(len(lines))
+	if more {
+		count += "+"
+	}
+
+	fmt.Println(fit(fmt.Sprintf("%s (%s)", title, count)))
+	if len(lines) < 1 {
+		fmt.Println("  -")
+	}
+	for _, l := range lines {
+		fmt.Println(fit("  " + l))
+	}
+	fmt.Println("")
+}
+
+func dashboardItems(title string, section rtc.DashboardSection, detail func(*rtc.WorkItem) string) {
+	lines := []string{}
+	for _, wi := range section.Items {
+		lines = append(lines, fmt.Sprintf("%-6s %-8s %s [%s]", wi.Id, wi.Type, wi.Summary, detail(wi)))
+	}
+
+	dashboardSection(title, lines, section.More)
+}
+
+func dashboard(resolvedDays int) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	now := time.Now()
+	d := r.GetDashboard(now.AddDate(0, 0, -resolvedDays), now.Add(-24*time.Hour))
+
+	fmt.Println("")
+	dashboardItems("In progress", d.InProgress, func(wi *rtc.WorkItem) string {
+		return wi.PlannedFor
+	})
+	dashboardItems("Mine in the current iteration", d.Current, func(wi *rtc.WorkItem) string {
+		return wi.State + ", " + valueOrNone(wi.Estimate)
+	})
+
+	approvals := []string{}
+	for _, p := range d.Approvals {
+		approvals = append(approvals, fmt.Sprintf("%-6s %-8s %s [%s, due %s]", p.WorkItem.Id, p.Approval.Type(), p.WorkItem.Summary, p.Approval.Name, formatDueDate(p.Approval.DueDate)))
+	}
+	dashboardSection("Approvals waiting for me", approvals, false)
+
+	dashboardItems(fmt.Sprintf("Created by me, resolved in the last %d days", resolvedDays), d.Resolved, func(wi *rtc.WorkItem) string {
+		return wi.State + ", " + wi.OwnedBy
+	})
+	dashboardItems("Subscribed, changed in the last 24h", d.Changed, func(wi *rtc.WorkItem) string {
+		return wi.State + ", " + wi.OwnedBy
+	})
+
+	if len(d.Errors) > 0 {
+		for _, err := range d.Errors {
+			fmt.Println(err.Error())
+		}
+		os.Exit(1)
+	}
+}
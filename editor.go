@@ -0,0 +1,60 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// EditText opens the user's $EDITOR on a temporary file with the given
+// initial content and returns what was saved, with lines starting with #
+// removed.
+func EditText(initial string) (string, error) {
+	f, err := ioutil.TempFile("", "rtc")
+	if err != nil {
+		return "", err
+	}
+
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(initial)
+	f.Close()
+	if err != nil {
+		return "", err
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], f.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Run()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+
+	lines := []string{}
+	for _, l := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(l, "#") {
+			lines = append(lines, l)
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
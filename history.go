@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fcoury/rtc-go/rtc"
+)
+
+func history(id string, since string, field string, asJson bool) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	sinceTime, err := parseSince(since)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	entries, err := r.GetHistory(id)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	entries = filterHistory(entries, sinceTime, field)
+
+	if asJson {
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	if len(entries) < 1 {
+		fmt.Println("No changes to be displayed.")
+		return
+	}
+
+	for _, e := range entries {
+		fmt.Printf("%s  %s\n", e.Modified.Local().Format("2006-01-02 15:04"), e.Author)
+		for _, c := range e.Changes {
+			fmt.Printf("    %s: %s -> %s\n", c.Field, valueOrNone(c.Old), valueOrNone(c.New))
+		}
+		fmt.Println("")
+	}
+}
+
+func filterHistory(entries []rtc.HistoryEntry, since time.Time, field string) []rtc.HistoryEntry {
+	field = strings.ToLower(field)
+	res := []rtc.HistoryEntry{}
+
+	for _, e := range entries {
+		if e.Modified.Before(since) {
+			continue
+		}
+
+		if field != "" {
+			changes := []rtc.Change{}
+			for _, c := range e.Changes {
+				if strings.Contains(strings.ToLower(c.Field), field) {
+					changes = append(changes, c)
+				}
+			}
+
+			if len(changes) < 1 {
+				continue
+			}
+			e.Changes = changes
+		}
+
+		res = append(res, e)
+	}
+
+	return res
+}
+
+// parseSince parses either a date, as in 2015-02-01, or a period to go back
+// from now, in hours (12h) or days (7d).
+func parseSince(since string) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", since, time.Local); err == nil {
+		return t, nil
+	}
+
+	if strings.HasSuffix(since, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(since, "d"))
+		if err == nil {
+			return time.Now().AddDate(0, 0, -days), nil
+		}
+	}
+
+	if d, err := time.ParseDuration(since); err == nil {
+		return time.Now().Add(-d), nil
+	}
+
+	return time.Time{}, errors.New("Invalid --since value " + since + ". Use a date like 2015-02-01 or a period like 12h or 7d.")
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+
+	return s
+}
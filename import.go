@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fcoury/rtc-go/rtc"
+	"github.com/gistia/tablewriter"
+	"gopkg.in/yaml.v2"
+)
+
+// csvColumns are the columns of the work items imported as CSV.
+var csvColumns = []string{"key", "parent", "type", "summary", "description", "estimate", "owner", "iteration", "category", "priority", "severity", "tags"}
+
+// csvExportColumns are the columns of csvColumns query results carry, which
+// is what gets exported.
+var csvExportColumns = []string{"key", "type", "summary", "estimate", "owner", "iteration", "category", "tags"}
+
+func writeCsv(w io.Writer, wis []*rtc.WorkItem) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvExportColumns); err != nil {
+		return err
+	}
+
+	for _, wi := range wis {
+		row := []string{wi.Id, wi.Type, wi.Summary, wi.Estimate, wi.OwnedBy, wi.PlannedFor, wi.FiledAgainst, strings.Join(wi.Tags, ",")}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func readCsvPlan(r io.Reader) ([]*rtc.PlanItem, error) {
+	cr := csv.NewReader(r)
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	cols := map[string]int{}
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	if _, ok := cols["summary"]; !ok {
+		return nil, errors.New("Missing the summary column. Use the columns: " + strings.Join(csvColumns, ", "))
+	}
+
+	items := []*rtc.PlanItem{}
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		get := func(col string) string {
+			if i, ok := cols[col]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		items = append(items, &rtc.PlanItem{
+			Key:         get("key"),
+			Parent:      get("parent"),
+			Type:        get("type"),
+			Summary:     get("summary"),
+			Description: get("description"),
+			Estimate:    get("estimate"),
+			Owner:       get("owner"),
+			Iteration:   get("iteration"),
+			Category:    get("category"),
+			Priority:    get("priority"),
+			Severity:    get("severity"),
+			Tags:        splitList([]string{get("tags")}),
+		})
+	}
+
+	return items, nil
+}
+
+func readPlan(file string) ([]*rtc.PlanItem, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".csv":
+		return readCsvPlan(f)
+	case ".yaml", ".yml":
+		data, err := ioutil.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+
+		var items []*rtc.PlanItem
+		if err := yaml.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+
+	return nil, errors.New("Unknown plan format " + file + ". Use a .yaml, .yml or .csv file.")
+}
+
+// mappingFile returns where the ids created for a plan are kept, next to it.
+func mappingFile(file string) string {
+	return strings.TrimSuffix(file, filepath.Ext(file)) + ".ids.json"
+}
+
+func readMapping(file string) (*rtc.ImportState, error) {
+	state := rtc.NewImportState()
+
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	saved := &rtc.ImportState{}
+	if err := json.Unmarshal(data, saved); err == nil && saved.Created != nil {
+		return saved, nil
+	}
+
+	// mapping files used to be just the created ids by key
+	if err := json.Unmarshal(data, &state.Created); err != nil {
+		return nil, errors.New("Invalid mapping file " + file + ": " + err.Error())
+	}
+
+	return state, nil
+}
+
+func writeMapping(file string, state *rtc.ImportState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, data, 0644)
+}
+
+func importPlan(file string, mapFile string, dryRun bool) {
+	items, err := readPlan(file)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if mapFile == "" {
+		mapFile = mappingFile(file)
+	}
+
+	state, err := readMapping(mapFile)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	fmt.Printf("Importing work items from %s...\n\n", file)
+	results, importErr := r.Import(items, state, dryRun, func(state *rtc.ImportState) error {
+		return writeMapping(mapFile, state)
+	})
+
+	if len(results) > 0 {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Key", "Id", "Type", "Summary", "Result"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetColWidth(appConfig.MaxWidth)
+
+		for _, res := range results {
+			table.Append([]string{res.Item.Key, res.Id, res.Item.Type, res.Item.Summary, res.Status})
+		}
+		table.Render()
+		fmt.Println("")
+	}
+
+	if dryRun {
+		fmt.Println("Dry run, nothing was created.")
+	} else {
+		fmt.Println("Created ids saved to", mapFile)
+	}
+
+	if importErr != nil {
+		fmt.Println(importErr.Error())
+		os.Exit(1)
+	}
+}
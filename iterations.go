@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fcoury/rtc-go/models"
+	"github.com/gistia/tablewriter"
+)
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "?"
+	}
+
+	return t.Local().Format("2006-01-02")
+}
+
+func dateRange(start time.Time, end time.Time) string {
+	if start.IsZero() && end.IsZero() {
+		return ""
+	}
+
+	return formatDate(start) + " - " + formatDate(end)
+}
+
+func daysLeft(start time.Time, end time.Time, now time.Time) string {
+	switch {
+	case end.IsZero():
+		return ""
+	case !now.Before(end):
+		return "ended"
+	case now.Before(start):
+		return fmt.Sprintf("starts in %d days", int(math.Ceil(start.Sub(now).Hours()/24)))
+	}
+
+	days := int(math.Ceil(end.Sub(now).Hours() / 24))
+	if days == 1 {
+		return "1 day left"
+	}
+
+	return fmt.Sprintf("%d days left", days)
+}
+
+func iterationLabel(label string, current string) string {
+	if current == "true" {
+		return label + " (current)"
+	}
+
+	return label
+}
+
+func iterations(all bool) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	iters, err := r.GetIterations()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	now := time.Now()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Id", "Iteration", "Dates", "Remaining"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetColWidth(appConfig.MaxWidth)
+
+	for i, iter := range iters {
+		if !all && iter.Completed == "true" {
+			continue
+		}
+		table.Append([]string{"#" + strconv.Itoa(i), iterationLabel(iter.Label, iter.Current), dateRange(iter.Start(), iter.End()), daysLeft(iter.Start(), iter.End(), now)})
+	}
+	table.Render()
+}
+
+func iterationTree(all bool) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	rels, err := r.GetReleases()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	now := time.Now()
+
+	for _, rel := range rels {
+		if !all && rel.Completed == "true" {
+			continue
+		}
+
+		fmt.Println(iterationLine(0, iterationLabel(rel.Label, rel.Current), rel.Start(), rel.End(), now))
+		printIterations(rel.Iterations, 1, all, now)
+	}
+}
+
+func printIterations(iters []models.Iteration, level int, all bool, now time.Time) {
+	for _, iter := range iters {
+		if !all && iter.Completed == "true" {
+			continue
+		}
+
+		fmt.Println(iterationLine(level, iterationLabel(iter.Label, iter.Current), iter.Start(), iter.End(), now))
+		printIterations(iter.Iterations, level+1, all, now)
+	}
+}
+
+func iterationLine(level int, label string, start time.Time, end time.Time, now time.Time) string {
+	line := strings.Repeat("    ", level) + label
+	if dates := dateRange(start, end); dates != "" {
+		line += "  [" + dates
+		if left := daysLeft(start, end, now); left != "" {
+			line += ", " + left
+		}
+		line += "]"
+	}
+
+	return line
+}
+
+func currentIteration() {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	iter, err := r.CurrentIteration()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	rels, err := r.GetReleases()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	now := time.Now()
+
+	for _, rel := range rels {
+		if containsIteration(rel.Iterations, iter.ItemId) {
+			fmt.Println("Release:  ", iterationLine(0, rel.Label, rel.Start(), rel.End(), now))
+			break
+		}
+	}
+
+	fmt.Println("Iteration:", iterationLine(0, iter.Label, iter.Start(), iter.End(), now))
+}
+
+func containsIteration(iters []models.Iteration, itemId string) bool {
+	for _, iter := range iters {
+		if iter.ItemId == itemId || containsIteration(iter.Iterations, itemId) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fcoury/rtc-go/rtc"
+	"github.com/gistia/tablewriter"
+)
+
+// JournalEntry is a log of work kept locally, one JSON object per line of
+// the journal file, to feed the timesheet.
+type JournalEntry struct {
+	Date    time.Time `json:"date"`
+	Id      string    `json:"id"`
+	Summary string    `json:"summary"`
+	Minutes int       `json:"minutes"`
+	Message string    `json:"message,omitempty"`
+}
+
+func (e JournalEntry) Duration() time.Duration {
+	return time.Duration(e.Minutes) * time.Minute
+}
+
+func appendJournal(e JournalEntry) error {
+	file, err := journalFile()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+func readJournal() ([]JournalEntry, error) {
+	entries := []JournalEntry{}
+
+	file, err := journalFile()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var e JournalEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, fmt.Errorf("Invalid journal entry in %s: %s", file, err.Error())
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, scanner.Err()
+}
+
+func logWork(id string, duration string, message string) {
+	d, err := rtc.ParseDuration(duration)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	d = wholeMinutes(d)
+
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	wi, err := r.Retrieve(id)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if err := recordWork(r, wi, d, message); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
+
+// recordWork logs the work on the work item and in the journal. Once the work
+// is logged on the work item a journal failure is only a warning, so callers
+// don't log it again.
+func recordWork(r *rtc.RTC, wi *rtc.WorkItem, d time.Duration, message string) error {
+	fmt.Printf("Logging %s on %s...\n", rtc.FormatDuration(d), wi.Title())
+	total, err := r.LogWork(wi.Id, d, message)
+	if err != nil {
+		return err
+	}
+
+	err = appendJournal(JournalEntry{
+		Date:    time.Now(),
+		Id:      wi.Id,
+		Summary: wi.Summary,
+		Minutes: int(d / time.Minute),
+		Message: message,
+	})
+	fmt.Println("Time spent is now", rtc.FormatDuration(total))
+	if err != nil {
+		fmt.Println("Warning: work logged, but failed to write to the journal: " + err.Error())
+	}
+
+	return nil
+}
+
+// wholeMinutes rounds the duration to the minute, as journal entries are kept.
+func wholeMinutes(d time.Duration) time.Duration {
+	return (d + time.Minute/2) / time.Minute * time.Minute
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek returns the monday of the week of the given time.
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
+
+func timesheet(since string, week bool, lastWeek bool) {
+	if (week && lastWeek) || (since != "" && (week || lastWeek)) {
+		fmt.Println("Use only one of --week, --last-week and --since.")
+		return
+	}
+
+	from := startOfWeek(time.Now())
+	to := from.AddDate(0, 0, 7)
+
+	if lastWeek {
+		from, to = from.AddDate(0, 0, -7), from
+	}
+
+	if since != "" {
+		t, err := parseSince(since)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		from, to = startOfDay(t), startOfDay(time.Now()).AddDate(0, 0, 1)
+	}
+
+	entries, err := readJournal()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	days := []time.Time{}
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+
+	// time per work item and day
+	spent := map[string]map[string]time.Duration{}
+	summaries := map[string]string{}
+	for _, e := range entries {
+		if e.Date.Before(from) || !e.Date.Before(to) {
+			continue
+		}
+
+		if spent[e.Id] == nil {
+			spent[e.Id] = map[string]time.Duration{}
+		}
+		spent[e.Id][e.Date.Format("2006-01-02")] += e.Duration()
+		summaries[e.Id] = e.Summary
+	}
+
+	if len(spent) < 1 {
+		fmt.Printf("No work logged from %s to %s.\n", from.Format("2006-01-02"), to.AddDate(0, 0, -1).Format("2006-01-02"))
+		return
+	}
+
+	ids := []string{}
+	for id := range spent {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	header := []string{"Id", "Summary"}
+	for _, d := range days {
+		header = append(header, d.Format("Mon 01/02"))
+	}
+	header = append(header, "Total")
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	dayTotals := make([]time.Duration, len(days))
+	var total time.Duration
+
+	for _, id := range ids {
+		row := []string{id, summaries[id]}
+		var itemTotal time.Duration
+		for i, d := range days {
+			t := spent[id][d.Format("2006-01-02")]
+			dayTotals[i] += t
+			itemTotal += t
+			row = append(row, formatHours(t))
+		}
+		total += itemTotal
+		table.Append(append(row, formatHours(itemTotal)))
+	}
+
+	row := []string{"", "Total"}
+	for _, t := range dayTotals {
+		row = append(row, formatHours(t))
+	}
+	table.Append(append(row, formatHours(total)))
+
+	table.Render()
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/fcoury/rtc-go/rtc"
+	"github.com/gistia/tablewriter"
+)
+
+func listLinks(id string) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	wi, err := r.GetWorkItem(id)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	fmt.Println(wi.Title())
+	fmt.Println("")
+
+	if len(wi.Links) < 1 {
+		fmt.Println("No links to be displayed.")
+		return
+	}
+
+	endpoints := []string{}
+	for e := range wi.Links {
+		endpoints = append(endpoints, e)
+	}
+	sort.Strings(endpoints)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Link", "Id", "Type", "Summary"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetColWidth(appConfig.MaxWidth)
+
+	for _, e := range endpoints {
+		for _, ref := range wi.Links[e] {
+			table.Append([]string{ref.LinkType, ref.Id, ref.Type, ref.Summary})
+		}
+	}
+	table.Render()
+
+	fmt.Println("\nLink types:", linkTypeNames())
+}
+
+func linkTypeNames() string {
+	s := ""
+	for i, lt := range rtc.LinkTypes {
+		if i > 0 {
+			s += ", "
+		}
+		s += lt.Endpoint
+	}
+
+	return s
+}
+
+func addLink(c *cli.Context) {
+	updateLinks(c, "Linking", "Linked to", func(r *rtc.RTC, id, linkType, targetId string) error {
+		return r.AddLink(id, linkType, targetId)
+	})
+}
+
+func removeLink(c *cli.Context) {
+	updateLinks(c, "Unlinking", "Unlinked from", func(r *rtc.RTC, id, linkType, targetId string) error {
+		return r.RemoveLink(id, linkType, targetId)
+	})
+}
+
+func updateLinks(c *cli.Context, verb string, done string, fn func(r *rtc.RTC, id, linkType, targetId string) error) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	ids, args, err := targetIds(r, c, false)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	if len(args) < 2 {
+		fmt.Println("Missing the link type and target work item")
+		os.Exit(1)
+	}
+
+	linkType, targetId := args[0], args[1]
+	runBulk(r, verb, ids, func(id string) (string, error) {
+		return fmt.Sprintf("%s %s (%s)", done, targetId, linkType), fn(r, id, linkType, targetId)
+	})
+}
+
+// reparent sets the parent of each of the comma separated work items, or
+// removes it when parentId is empty, carrying on past the ones that fail.
+func reparent(ids string, parentId string, dryRun bool) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	newParent := "(none)"
+	if parentId != "" {
+		pwi, err := r.Retrieve(parentId)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		newParent = pwi.Title()
+	}
+
+	failed := 0
+	for _, id := range strings.Split(ids, ",") {
+		id = strings.TrimSpace(id)
+
+		wi, err := r.GetWorkItem(id)
+		if err != nil {
+			fmt.Println(err.Error())
+			failed++
+			continue
+		}
+
+		oldParent := "(none)"
+		if len(wi.Parents) > 0 {
+			p := wi.Parents[0]
+			oldParent = fmt.Sprintf("%s %s - %s", p.Type, p.Id, p.Summary)
+		}
+
+		fmt.Println(wi.Title())
+		fmt.Printf("    %s -> %s\n", oldParent, newParent)
+
+		if dryRun {
+			continue
+		}
+
+		if parentId == "" {
+			if len(wi.Parents) < 1 {
+				continue
+			}
+			err = r.RemoveParent(id)
+		} else {
+			err = r.SetParent(id, parentId)
+		}
+
+		if err != nil {
+			fmt.Println("    FAILED: " + err.Error())
+			failed++
+		}
+	}
+
+	if dryRun {
+		fmt.Println("\nDry run, nothing was changed.")
+	} else if failed == 0 {
+		fmt.Println("\nParents successfully changed.")
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%d work item(s) failed.\n", failed)
+		os.Exit(1)
+	}
+}
@@ -3,18 +3,21 @@ package main
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/codegangsta/cli"
-	"github.com/fcoury/rtc-go/models"
 	"github.com/fcoury/rtc-go/rtc"
 	"github.com/gistia/tablewriter"
 	"github.com/kennygrant/sanitize"
 )
 
 var appConfig *Config
+var merge bool
 
 type UpdateAttrs struct {
 	Estimate  string
@@ -34,9 +37,122 @@ type Query struct {
 	Summary    string
 	Parent     string
 	Owner      string
+	Type       string
+	Priority   string
+	Severity   string
+	Category   string
+	Iteration  string
 	Sort       string
 	SortAsc    bool
-	MaxResults int
+	MaxResults int // 0 for every matching work item
+	Output     string
+}
+
+var queryFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "summary",
+		Value: "",
+		Usage: "Work items that the summary contain the words",
+	},
+	cli.StringFlag{
+		Name:  "parent",
+		Value: "",
+		Usage: "Work items with the given parent id",
+	},
+	cli.StringFlag{
+		Name:  "owner",
+		Value: "",
+		Usage: "Filters by the owner name",
+	},
+	cli.StringFlag{
+		Name:  "type",
+		Value: "",
+		Usage: "Filters by the work item type",
+	},
+	cli.StringFlag{
+		Name:  "priority",
+		Value: "",
+		Usage: "Filters by the priority label",
+	},
+	cli.StringFlag{
+		Name:  "severity",
+		Value: "",
+		Usage: "Filters by the severity label",
+	},
+	cli.StringFlag{
+		Name:  "category",
+		Value: "",
+		Usage: "Filters by the filed against category",
+	},
+	cli.StringFlag{
+		Name:  "iteration",
+		Value: "",
+		Usage: "Filters by the planned for iteration label",
+	},
+	cli.StringFlag{
+		Name:  "sort",
+		Value: "modified",
+		Usage: "Field to sort by",
+	},
+	cli.IntFlag{
+		Name:  "maxresults",
+		Value: 15,
+		Usage: "How many results to display",
+	},
+	cli.BoolFlag{
+		Name:  "mine",
+		Usage: "Everything assigned to me",
+	},
+	cli.BoolFlag{
+		Name:  "closed",
+		Usage: "All work items that are closed",
+	},
+	cli.BoolFlag{
+		Name:  "open",
+		Usage: "All work items that are open or in progress",
+	},
+	cli.BoolFlag{
+		Name:  "current",
+		Usage: "Shows only work items for current iteration",
+	},
+	cli.BoolFlag{
+		Name:  "asc",
+		Usage: "Sorts by ascending order (descending is default)",
+	},
+	cli.StringFlag{
+		Name:  "o",
+		Value: "table",
+		Usage: "Output format: table, csv or ids, one per line",
+	},
+}
+
+// flagGetter is satisfied both by the command line context and by the flag
+// sets parsed from a --from-query spec.
+type flagGetter interface {
+	String(name string) string
+	Bool(name string) bool
+	Int(name string) int
+}
+
+func newQuery(c flagGetter) Query {
+	return Query{
+		Mine:       c.Bool("mine"),
+		Resolved:   c.Bool("closed"),
+		Unresolved: c.Bool("open"),
+		Current:    c.Bool("current"),
+		Summary:    c.String("summary"),
+		Parent:     c.String("parent"),
+		Owner:      c.String("owner"),
+		Type:       c.String("type"),
+		Priority:   c.String("priority"),
+		Severity:   c.String("severity"),
+		Category:   c.String("category"),
+		Iteration:  c.String("iteration"),
+		Sort:       c.String("sort"),
+		SortAsc:    c.Bool("asc"),
+		MaxResults: c.Int("maxresults"),
+		Output:     c.String("o"),
+	}
 }
 
 func (q Query) Check() error {
@@ -46,11 +162,42 @@ func (q Query) Check() error {
 		}
 	}
 
+	if q.Output != "" && q.Output != "table" && q.Output != "csv" && q.Output != "ids" {
+		return errors.New("Unknown output format " + q.Output + ". Use table, csv or ids.")
+	}
+
 	return nil
 }
 
 func (u UpdateAttrs) HasAttributes() bool {
-	return u.Estimate != "" && u.TimeSpent != "" && u.Iteration != ""
+	return u.Estimate != "" || u.TimeSpent != "" || u.Iteration != ""
+}
+
+func (u UpdateAttrs) Action() (string, error) {
+	actions := []string{}
+
+	if u.Start {
+		actions = append(actions, "start working")
+	}
+	if u.Resolve {
+		actions = append(actions, "resolve")
+	}
+	if u.Close {
+		actions = append(actions, "close")
+	}
+	if u.Reopen {
+		actions = append(actions, "reopen")
+	}
+
+	if len(actions) > 1 {
+		return "", errors.New("Can't use more than one of --start, --resolve, --close and --reopen")
+	}
+
+	if len(actions) < 1 {
+		return "", nil
+	}
+
+	return actions[0], nil
 }
 
 func main() {
@@ -65,6 +212,14 @@ func main() {
 			Name:  "verbose",
 			Usage: "Display verbose logs",
 		},
+		cli.BoolFlag{
+			Name:  "merge",
+			Usage: "Retries saves that conflict with someone else's changes to other fields",
+		},
+	}
+	app.Before = func(c *cli.Context) error {
+		merge = c.Bool("merge")
+		return nil
 	}
 	app.Commands = []cli.Command{
 		{
@@ -83,6 +238,22 @@ func main() {
 			},
 		},
 
+		{
+			Name:      "dashboard",
+			ShortName: "d",
+			Usage:     "shows your work at a glance: in progress, current iteration, approvals, resolved and changed items",
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "resolved-days",
+					Value: 7,
+					Usage: "How many days back to look for resolved work items you created",
+				},
+			},
+			Action: func(c *cli.Context) {
+				dashboard(c.Int("resolved-days"))
+			},
+		},
+
 		{
 			Name:      "find",
 			ShortName: "f",
@@ -101,9 +272,14 @@ func main() {
 					Name:  "summary",
 					Usage: "Omits the description of the work item",
 				},
+				cli.IntFlag{
+					Name:  "comments",
+					Value: 3,
+					Usage: "How many of the latest comments to display",
+				},
 			},
 			Action: func(c *cli.Context) {
-				info(c.Args()[0], c.Bool("summary"))
+				info(c.Args()[0], c.Bool("summary"), c.Int("comments"))
 			},
 		},
 
@@ -116,9 +292,14 @@ func main() {
 					Name:  "summary",
 					Usage: "Omits the description of the work item",
 				},
+				cli.IntFlag{
+					Name:  "comments",
+					Value: 3,
+					Usage: "How many of the latest comments to display",
+				},
 			},
 			Action: func(c *cli.Context) {
-				info(c.Args()[0], c.Bool("summary"))
+				info(c.Args()[0], c.Bool("summary"), c.Int("comments"))
 			},
 		},
 
@@ -137,31 +318,297 @@ func main() {
 					Value: "",
 					Usage: "Id of the parent task, if any",
 				},
+				cli.StringFlag{
+					Name:  "desc",
+					Value: "",
+					Usage: "Description of the work item",
+				},
+				cli.StringFlag{
+					Name:  "desc-file",
+					Value: "",
+					Usage: "Reads the description from a file, or stdin with -",
+				},
+				cli.BoolFlag{
+					Name:  "e",
+					Usage: "Writes the description using $EDITOR",
+				},
+				cli.StringFlag{
+					Name:  "owner",
+					Value: "",
+					Usage: "Owner name, defaults to you",
+				},
+				cli.StringFlag{
+					Name:  "category",
+					Value: "",
+					Usage: "Filed against category",
+				},
+				cli.StringFlag{
+					Name:  "iteration",
+					Value: "",
+					Usage: "Planned for iteration, by label, current, next or previous",
+				},
+				cli.StringFlag{
+					Name:  "priority",
+					Value: "",
+					Usage: "Priority label",
+				},
+				cli.StringFlag{
+					Name:  "severity",
+					Value: "",
+					Usage: "Severity label",
+				},
+				cli.StringSliceFlag{
+					Name:  "tag",
+					Value: &cli.StringSlice{},
+					Usage: "Adds a tag, can be repeated or comma separated",
+				},
+				cli.StringFlag{
+					Name:  "estimate",
+					Value: "",
+					Usage: "Estimated time for the work item",
+				},
 			},
 			Action: func(c *cli.Context) {
-				create(c.Args()[0], c.String("type"), c.String("parent"))
+				if len(c.Args()) < 1 {
+					fmt.Println("Usage: rtc create <summary>")
+					return
+				}
+
+				wi := &rtc.WorkItem{
+					Summary:      strings.Join(c.Args(), " "),
+					Type:         c.String("type"),
+					Description:  c.String("desc"),
+					OwnedBy:      c.String("owner"),
+					FiledAgainst: c.String("category"),
+					PlannedFor:   c.String("iteration"),
+					Priority:     c.String("priority"),
+					Severity:     c.String("severity"),
+					Tags:         splitList(c.StringSlice("tag")),
+					Estimate:     c.String("estimate"),
+				}
+				create(wi, c.String("parent"), c.String("desc-file"), c.Bool("e"))
+			},
+		},
+
+		{
+			Name:  "clone",
+			Usage: "creates a copy of a work item",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "with-children",
+					Usage: "Copies the children recursively",
+				},
+				cli.StringFlag{
+					Name:  "iteration",
+					Value: "",
+					Usage: "Plans the copies for an iteration, by label, current, next or previous",
+				},
+				cli.StringFlag{
+					Name:  "prefix",
+					Value: "",
+					Usage: "Prepended to the summary of the copies",
+				},
+				cli.StringFlag{
+					Name:  "link",
+					Value: "copiedFrom",
+					Usage: "Links the copies to the originals as copiedFrom, related or none",
+				},
+			},
+			Action: func(c *cli.Context) {
+				opts := rtc.CloneOptions{
+					Prefix:       c.String("prefix"),
+					Iteration:    c.String("iteration"),
+					LinkType:     c.String("link"),
+					WithChildren: c.Bool("with-children"),
+				}
+				if opts.LinkType == "none" {
+					opts.LinkType = ""
+				}
+				clone(c.Args()[0], opts)
+			},
+		},
+
+		{
+			Name:  "log",
+			Usage: "logs time spent on a work item: log <id> <duration, as in 1h30m>",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "m",
+					Value: "",
+					Usage: "What the time was spent on",
+				},
+			},
+			Action: func(c *cli.Context) {
+				if len(c.Args()) < 2 {
+					fmt.Println("Usage: rtc log <id> <duration> [-m message]")
+					return
+				}
+				logWork(c.Args()[0], strings.Join(c.Args()[1:], " "), c.String("m"))
+			},
+		},
+
+		{
+			Name:  "timer",
+			Usage: "times the work on a work item and logs it when stopped",
+			Subcommands: []cli.Command{
+				{
+					Name:  "start",
+					Usage: "starts timing a work item, stopping the running timer: timer start <id>",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "work",
+							Usage: "Also performs the start working action on the work item",
+						},
+					},
+					Action: func(c *cli.Context) {
+						timerStart(c.Args()[0], c.Bool("work"))
+					},
+				},
+				{
+					Name:  "stop",
+					Usage: "stops the timer and logs the time elapsed",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "m",
+							Value: "",
+							Usage: "What the time was spent on",
+						},
+					},
+					Action: func(c *cli.Context) {
+						timerStop(c.String("m"))
+					},
+				},
+				{
+					Name:  "status",
+					Usage: "shows the timer running",
+					Action: func(c *cli.Context) {
+						timerStatus()
+					},
+				},
+			},
+		},
+
+		{
+			Name:      "timesheet",
+			ShortName: "ts",
+			Usage:     "shows the time logged per day and work item, for the current week by default",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "week",
+					Usage: "Time logged in the current week",
+				},
+				cli.BoolFlag{
+					Name:  "last-week",
+					Usage: "Time logged in the last week",
+				},
+				cli.StringFlag{
+					Name:  "since",
+					Value: "",
+					Usage: "Time logged since a date (2015-02-01) or for a period (7d)",
+				},
+			},
+			Action: func(c *cli.Context) {
+				timesheet(c.String("since"), c.Bool("week"), c.Bool("last-week"))
+			},
+		},
+
+		{
+			Name:  "report",
+			Usage: "reports on the work of iterations",
+			Subcommands: []cli.Command{
+				{
+					Name:  "sprint",
+					Usage: "summarises the work items of an iteration, the current one by default: report sprint [iteration]",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "format",
+							Value: "chart",
+							Usage: "Output format: chart, csv (burndown) or json",
+						},
+					},
+					Action: func(c *cli.Context) {
+						iterId := strings.Join(c.Args(), " ")
+						if iterId == "" {
+							iterId = "current"
+						}
+						sprintReport(iterId, c.String("format"))
+					},
+				},
+				{
+					Name:  "velocity",
+					Usage: "shows the estimate completed in each of the last iterations",
+					Flags: []cli.Flag{
+						cli.IntFlag{
+							Name:  "last",
+							Value: 6,
+							Usage: "How many iterations to show",
+						},
+					},
+					Action: func(c *cli.Context) {
+						velocityReport(c.Int("last"))
+					},
+				},
+			},
+		},
+
+		{
+			Name:  "workload",
+			Usage: "shows the open work of each owner in an iteration against their capacity",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "iteration, i",
+					Value: "current",
+					Usage: "Iteration id, label or current/next/previous",
+				},
+			},
+			Action: func(c *cli.Context) {
+				workload(c.String("iteration"))
+			},
+		},
+
+		{
+			Name:      "import",
+			ShortName: "imp",
+			Usage:     "creates the work items described in a YAML or CSV plan: import <plan.yaml>",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Shows what would be created without creating it",
+				},
+				cli.StringFlag{
+					Name:  "map",
+					Value: "",
+					Usage: "File keeping the ids created for each key, defaults to <plan>.ids.json",
+				},
+			},
+			Action: func(c *cli.Context) {
+				if len(c.Args()) < 1 {
+					fmt.Println("Usage: rtc import <plan.yaml|plan.csv>")
+					return
+				}
+				importPlan(c.Args()[0], c.String("map"), c.Bool("dry-run"))
 			},
 		},
 
 		{
 			Name:      "update",
 			ShortName: "u",
-			Usage:     "updates a work item",
+			Usage:     "updates work items: update <id> [<id>...] | - | --from-query <query>",
 			Flags: []cli.Flag{
 				cli.StringFlag{
 					Name:  "estimate",
 					Value: "",
-					Usage: "Updates the estimated time for the work item",
+					Usage: "Updates the estimated time for the work item, as in 8h or 1d",
 				},
 				cli.StringFlag{
 					Name:  "timespent",
 					Value: "",
-					Usage: "Updates the time spent working on the work item",
+					Usage: "Updates the time spent working on the work item, as in 2h30m (see log to add to it)",
 				},
 				cli.StringFlag{
 					Name:  "iteration",
 					Value: "",
-					Usage: "Updates the iteration the work item (see iterations command)",
+					Usage: "Updates the iteration of the work item, by label, current, next or previous",
 				},
 				cli.BoolFlag{
 					Name:  "start",
@@ -179,6 +626,7 @@ func main() {
 					Name:  "reopen",
 					Usage: "Reopens the work item",
 				},
+				fromQueryFlag,
 			},
 			Action: func(c *cli.Context) {
 				attrs := UpdateAttrs{
@@ -190,7 +638,7 @@ func main() {
 					Close:     c.Bool("close"),
 					Reopen:    c.Bool("reopen"),
 				}
-				update(c.Args()[0], attrs)
+				update(c, attrs)
 			},
 		},
 
@@ -198,76 +646,49 @@ func main() {
 			Name:      "query",
 			ShortName: "q",
 			Usage:     "queries work items",
-			Flags: []cli.Flag{
-				cli.StringFlag{
-					Name:  "summary",
-					Value: "",
-					Usage: "Work items that the summary contain the words",
-				},
-				cli.StringFlag{
-					Name:  "parent",
-					Value: "",
-					Usage: "Work items with the given parent id",
-				},
-				cli.StringFlag{
-					Name:  "owner",
-					Value: "",
-					Usage: "Filters by the owner name",
-				},
-				cli.StringFlag{
-					Name:  "sort",
-					Value: "modified",
-					Usage: "Field to sort by",
-				},
-				cli.IntFlag{
-					Name:  "maxresults",
-					Value: 15,
-					Usage: "How many results to display",
-				},
-				cli.BoolFlag{
-					Name:  "mine",
-					Usage: "Everything assigned to me",
-				},
-				cli.BoolFlag{
-					Name:  "closed",
-					Usage: "All work items that are closed",
-				},
-				cli.BoolFlag{
-					Name:  "open",
-					Usage: "All work items that are open or in progress",
-				},
-				cli.BoolFlag{
-					Name:  "current",
-					Usage: "Shows only work items for current iteration",
-				},
-				cli.BoolFlag{
-					Name:  "asc",
-					Usage: "Sorts by ascending order (descending is default)",
-				},
+			Flags:     queryFlags,
+			Action: func(c *cli.Context) {
+				query(newQuery(c))
 			},
+		},
+
+		{
+			Name:  "tui",
+			Usage: "browses and triages work items full screen, those of the query or, without one, of the built-in saved query of your open work items; S picks another saved query",
+			Flags: queryFlags,
 			Action: func(c *cli.Context) {
-				q := Query{
-					Mine:       c.Bool("mine"),
-					Resolved:   c.Bool("closed"),
-					Unresolved: c.Bool("open"),
-					Current:    c.Bool("current"),
-					Summary:    c.String("summary"),
-					Parent:     c.String("parent"),
-					Owner:      c.String("owner"),
-					Sort:       c.String("sort"),
-					SortAsc:    c.Bool("asc"),
-					MaxResults: c.Int("maxresults"),
+				q := newQuery(c)
+				if !c.IsSet("maxresults") {
+					q.MaxResults = tuiMaxResults
 				}
-				query(q)
+				runTui(q, c.NumFlags() == 0)
 			},
 		},
 
 		{
 			Name:      "close",
 			ShortName: "cl",
-			Usage:     "closes a work item",
+			Usage:     "closes work items: close <id> [<id>...] | - | --from-query <query>",
+			Flags:     []cli.Flag{fromQueryFlag},
+			Action: func(c *cli.Context) {
+				close(c)
+			},
+		},
+
+		{
+			Name:      "transition",
+			ShortName: "tr",
+			Usage:     "performs a workflow action on work items, or lists the available ones: transition <id>[,<id>...] | - [action]",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "resolution",
+					Value: "",
+					Usage: "Resolution to set, such as Fixed or Duplicate",
+				},
+				fromQueryFlag,
+			},
 			Action: func(c *cli.Context) {
-				close(c.Args()[0])
+				transition(c, c.String("resolution"))
 			},
 		},
 
@@ -276,28 +697,95 @@ func main() {
 			ShortName: "app",
 			Usage:     "adds an approval to an user story",
 			Flags: []cli.Flag{
-				cli.StringFlag{
+				cli.StringSliceFlag{
 					Name:  "approver",
-					Value: "",
-					Usage: "Assigns a approver",
+					Value: &cli.StringSlice{},
+					Usage: "Assigns an approver, can be repeated or comma separated",
 				},
 				cli.StringFlag{
 					Name:  "desc",
 					Value: "Approval",
 					Usage: "Approval description",
 				},
+				cli.StringFlag{
+					Name:  "type",
+					Value: "approval",
+					Usage: "Approval type: approval, review or verification",
+				},
+				cli.StringFlag{
+					Name:  "due",
+					Value: "",
+					Usage: "Due date, as in 2015-02-28",
+				},
 			},
 			Action: func(c *cli.Context) {
-				addApproval(c.Args()[0], c.String("desc"), c.String("approver"))
+				addApproval(c.Args()[0], c.String("desc"), c.StringSlice("approver"), c.String("type"), c.String("due"))
+			},
+		},
+
+		{
+			Name:      "approvals",
+			ShortName: "apps",
+			Usage:     "lists and decides on approvals, reviews and verifications",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "pending-for-me",
+					Usage: "Lists the approvals of open work items waiting for you",
+				},
+			},
+			Action: func(c *cli.Context) {
+				if c.Bool("pending-for-me") {
+					pendingApprovals()
+					return
+				}
+				cli.ShowSubcommandHelp(c)
+			},
+			Subcommands: []cli.Command{
+				{
+					Name:  "ls",
+					Usage: "lists the approvals of a work item",
+					Action: func(c *cli.Context) {
+						listApprovals(c.Args()[0])
+					},
+				},
+				{
+					Name:  "approve",
+					Usage: "approves: approvals approve <id> <approval id>",
+					Action: func(c *cli.Context) {
+						setApprovalState(c.Args()[0], c.Args()[1], "approved")
+					},
+				},
+				{
+					Name:  "reject",
+					Usage: "rejects: approvals reject <id> <approval id>",
+					Action: func(c *cli.Context) {
+						setApprovalState(c.Args()[0], c.Args()[1], "rejected")
+					},
+				},
+				{
+					Name:  "reset",
+					Usage: "sets your decision back to pending: approvals reset <id> <approval id>",
+					Action: func(c *cli.Context) {
+						setApprovalState(c.Args()[0], c.Args()[1], "pending")
+					},
+				},
+				{
+					Name:  "rm",
+					Usage: "removes an approval: approvals rm <id> <approval id>",
+					Action: func(c *cli.Context) {
+						removeApproval(c.Args()[0], c.Args()[1])
+					},
+				},
 			},
 		},
 
 		{
 			Name:      "move",
 			ShortName: "mv",
-			Usage:     "moves a work item to a given iteration (see iters)",
+			Usage:     "moves work items to an iteration, by label, #index of the iterations command, current, next or previous: move <id>[,<id>...] | - <iteration>",
+			Flags:     []cli.Flag{fromQueryFlag},
 			Action: func(c *cli.Context) {
-				move(c.Args()[0], c.Args()[1])
+				move(c)
 			},
 		},
 
@@ -313,15 +801,24 @@ func main() {
 		{
 			Name:      "subtask",
 			ShortName: "st",
-			Usage:     "creates a subtask for a story",
+			Usage:     "creates a subtask for a story, or the whole set of a template",
 			Flags: []cli.Flag{
 				cli.StringFlag{
 					Name:  "type",
 					Value: "Artifacts",
 					Usage: "Creates a subtask of a given type",
 				},
+				cli.StringFlag{
+					Name:  "template",
+					Value: "",
+					Usage: "Creates the subtasks of a template from the config, such as story-dod",
+				},
 			},
 			Action: func(c *cli.Context) {
+				if c.String("template") != "" {
+					createSubtasks(c.Args()[0], c.String("template"))
+					return
+				}
 				createSubtask(c.Args()[0], c.String("type"))
 			},
 		},
@@ -350,20 +847,167 @@ func main() {
 		},
 
 		{
-			Name:      "open",
-			ShortName: "o",
-			Usage:     "opens the work item on the RTC web interface",
+			Name:      "open",
+			ShortName: "o",
+			Usage:     "opens the work item on the RTC web interface",
+			Action: func(c *cli.Context) {
+				open(c.Args()[0])
+			},
+		},
+
+		{
+			Name:      "comment",
+			ShortName: "cm",
+			Usage:     "adds a comment to work items: comment <id>[,<id>...] | - <text>",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "e",
+					Usage: "Writes the comment using $EDITOR",
+				},
+				fromQueryFlag,
+			},
+			Action: func(c *cli.Context) {
+				comment(c, c.Bool("e"))
+			},
+		},
+
+		{
+			Name:  "link",
+			Usage: "lists, adds and removes links between work items",
+			Subcommands: []cli.Command{
+				{
+					Name:  "ls",
+					Usage: "lists the links of a work item",
+					Action: func(c *cli.Context) {
+						listLinks(c.Args()[0])
+					},
+				},
+				{
+					Name:  "add",
+					Usage: "links work items to another: link add <id>[,<id>...] | - <type> <target id>",
+					Flags: []cli.Flag{fromQueryFlag},
+					Action: func(c *cli.Context) {
+						addLink(c)
+					},
+				},
+				{
+					Name:  "rm",
+					Usage: "removes a link of work items: link rm <id>[,<id>...] | - <type> <target id>",
+					Flags: []cli.Flag{fromQueryFlag},
+					Action: func(c *cli.Context) {
+						removeLink(c)
+					},
+				},
+			},
+		},
+
+		{
+			Name:      "reparent",
+			ShortName: "rp",
+			Usage:     "changes the parent of work items: reparent <id>[,<id>...] <new parent id>",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Shows what would change without saving",
+				},
+			},
+			Action: func(c *cli.Context) {
+				reparent(c.Args()[0], c.Args()[1], c.Bool("dry-run"))
+			},
+		},
+
+		{
+			Name:  "unparent",
+			Usage: "removes the parent of work items: unparent <id>[,<id>...]",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Shows what would change without saving",
+				},
+			},
+			Action: func(c *cli.Context) {
+				reparent(c.Args()[0], "", c.Bool("dry-run"))
+			},
+		},
+
+		{
+			Name:  "attach",
+			Usage: "lists, downloads and uploads work item attachments",
+			Subcommands: []cli.Command{
+				{
+					Name:  "ls",
+					Usage: "lists the attachments of a work item",
+					Action: func(c *cli.Context) {
+						listAttachments(c.Args()[0])
+					},
+				},
+				{
+					Name:  "get",
+					Usage: "downloads an attachment: attach get <id> <attachment id>",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "out",
+							Value: "",
+							Usage: "File to save to, - for the standard output (defaults to the attachment name)",
+						},
+					},
+					Action: func(c *cli.Context) {
+						getAttachment(c.Args()[0], c.Args()[1], c.String("out"))
+					},
+				},
+				{
+					Name:  "put",
+					Usage: "uploads files as attachments: attach put <id> <file>...",
+					Action: func(c *cli.Context) {
+						putAttachments(c.Args()[0], c.Args()[1:])
+					},
+				},
+			},
+		},
+
+		{
+			Name:      "history",
+			ShortName: "hist",
+			Usage:     "shows the history of changes of a work item",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "since",
+					Value: "",
+					Usage: "Only changes since a date (2015-02-01) or for a period (12h, 7d)",
+				},
+				cli.StringFlag{
+					Name:  "field",
+					Value: "",
+					Usage: "Only changes to the fields that contain the given name",
+				},
+				cli.BoolFlag{
+					Name:  "json",
+					Usage: "Outputs the history as JSON",
+				},
+			},
 			Action: func(c *cli.Context) {
-				open(c.Args()[0])
+				history(c.Args()[0], c.String("since"), c.String("field"), c.Bool("json"))
 			},
 		},
 
 		{
 			Name:      "tree",
 			ShortName: "t",
-			Usage:     "shows workitem parents and children, if any",
+			Usage:     "shows the hierarchy of a work item, from its root down to its descendants",
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "depth",
+					Value: 3,
+					Usage: "How many levels of descendants to display",
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Value: "ascii",
+					Usage: "Output format: ascii, json or dot",
+				},
+			},
 			Action: func(c *cli.Context) {
-				tree(c.Args()[0])
+				tree(c.Args()[0], c.Int("depth"), c.String("format"))
 			},
 		},
 
@@ -409,8 +1053,24 @@ func main() {
 					Name:  "all",
 					Usage: "Shows completed iterations",
 				},
+				cli.BoolFlag{
+					Name:  "tree",
+					Usage: "Shows the iterations under their releases",
+				},
+				cli.BoolFlag{
+					Name:  "current",
+					Usage: "Shows only the current iteration",
+				},
 			},
 			Action: func(c *cli.Context) {
+				if c.Bool("current") {
+					currentIteration()
+					return
+				}
+				if c.Bool("tree") {
+					iterationTree(c.Bool("all"))
+					return
+				}
 				iterations(c.Bool("all"))
 			},
 		},
@@ -437,6 +1097,8 @@ func main() {
 
 func login() (*rtc.RTC, error) {
 	res := rtc.NewRTC(appConfig.User, appConfig.Pass, appConfig.OwnerId)
+	res.Merge = merge
+	res.Defaults = appConfig.Defaults
 	err := res.Login()
 
 	if err != nil {
@@ -534,7 +1196,7 @@ func showTree(wi *rtc.WorkItem) {
 	}
 }
 
-func info(id string, summary bool) {
+func info(id string, summary bool, comments int) {
 	r, err := login()
 	if err != nil {
 		fmt.Println(err.Error())
@@ -598,99 +1260,183 @@ func info(id string, summary bool) {
 		}
 	}
 
+	if !summary && comments > 0 && len(wi.Comments) > 0 {
+		fmt.Println(strings.Repeat("-", len(title)))
+		fmt.Println("")
+		fmt.Println("Comments:")
+		fmt.Println("")
+		showComments(wi.Comments, comments)
+	}
+
 	fmt.Println("")
 	// fmt.Printf("Id: %s\nType: %s\nSummary: %s\nPlanned for: %s\nCreated by: %s\nOwner: %s\n\nDescription:\n%s\n",
 	// 	wi.Id, wi.Type, wi.Summary, wi.PlannedFor, wi.CreatedBy, wi.OwnedBy, desc)
 }
 
-func tree(id string) {
+func showComments(comments []rtc.Comment, last int) {
+	if last < len(comments) {
+		comments = comments[len(comments)-last:]
+	}
+
+	for _, c := range comments {
+		fmt.Printf("  %s - %s\n", c.Author, c.Created.Local().Format("2006-01-02 15:04"))
+		text := strings.TrimSpace(sanitize.HTML(strings.Replace(c.HTML, "<br/>", "\n", -1)))
+		for _, l := range strings.Split(text, "\n") {
+			fmt.Printf("    %s\n", l)
+		}
+		fmt.Println("")
+	}
+}
+
+func comment(c *cli.Context, edit bool) {
 	r, err := login()
 	if err != nil {
 		fmt.Println(err.Error())
-		return
+		os.Exit(1)
 	}
 
-	wi, err := r.GetWorkItem(id)
+	ids, args, err := targetIds(r, c, false)
 	if err != nil {
 		fmt.Println(err.Error())
-		return
+		os.Exit(1)
 	}
 
-	fmt.Println(wi.Title())
-	fmt.Println("")
-	showTree(wi)
+	text := strings.Join(args, " ")
+	if edit {
+		text, err = EditText(text + "\n# Write your comment for work items " + strings.Join(ids, ", ") + ". Lines starting with # are ignored.\n")
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if strings.TrimSpace(text) == "" {
+		fmt.Println("Missing the comment text")
+		os.Exit(1)
+	}
+
+	runBulk(r, "Commenting on", ids, func(id string) (string, error) {
+		return "Comment added", r.AddComment(id, text)
+	})
 }
 
-func update(id string, attrs UpdateAttrs) {
+func update(c *cli.Context, attrs UpdateAttrs) {
 	r, err := login()
 	if err != nil {
 		fmt.Println(err.Error())
-		return
+		os.Exit(1)
 	}
 
-	if attrs.Start {
-		fmt.Println("Attempting to start work item %s...\n", id)
-		err = r.PerformAction("start", id, "startWorking", "Started")
-		if err != nil {
-			fmt.Println(err.Error())
-			return
-		}
+	action, err := attrs.Action()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
 	}
 
-	if attrs.Resolve {
-		fmt.Println("Attempting to resolve work item %s...\n", id)
-		err = r.PerformAction("resolve", id, "resolve", "Resolved")
-		if err != nil {
-			fmt.Println(err.Error())
-			return
-		}
+	if !attrs.HasAttributes() && action == "" {
+		fmt.Println("Nothing to update. Use --help to see the available options.")
+		os.Exit(1)
 	}
 
-	if attrs.Close {
-		fmt.Println("Attempting to close work item %s...\n", id)
-		err = r.PerformAction("close", id, "close", "Closed")
-		if err != nil {
-			fmt.Println(err.Error())
-			return
-		}
+	ids, _, err := targetIds(r, c, true)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
 	}
 
-	if attrs.Reopen {
-		fmt.Println("Attempting to reopen work item %s...\n", id)
-		err = r.PerformAction("reopen", id, "reopen", "Reopened")
-		if err != nil {
-			fmt.Println(err.Error())
-			return
-		}
+	wi := rtc.WorkItem{
+		Estimate:    attrs.Estimate,
+		TimeSpent:   attrs.TimeSpent,
+		IterationId: attrs.Iteration,
 	}
 
-	if attrs.HasAttributes() {
-		wi := rtc.WorkItem{
-			Id:          id,
-			Estimate:    attrs.Estimate,
-			TimeSpent:   attrs.TimeSpent,
-			IterationId: attrs.Iteration,
-		}
+	if len(ids) != 1 || c.String("from-query") != "" {
+		runBulk(r, "Updating", ids, func(id string) (string, error) {
+			item := wi
+			item.Id = id
+			changes, err := r.Update(item, action)
+			return describeChanges(changes), err
+		})
+		return
+	}
 
-		fmt.Printf("Updating work item %s...\n", id)
-		err = r.Update(wi)
-		if err != nil {
-			fmt.Println(err.Error())
-			return
+	id := ids[0]
+	wi.Id = id
+
+	fmt.Printf("Updating work item %s...\n", id)
+	changes, err := r.Update(wi, action)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	if len(changes) < 1 {
+		fmt.Println("Work item already up to date, nothing changed.")
+		return
+	}
+
+	showChanges(changes)
+
+	fmt.Println("\nWork item successfully updated.")
+}
+
+// splitList splits repeated and comma separated flag values, dropping the
+// empty ones.
+func splitList(values []string) []string {
+	l := []string{}
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				l = append(l, s)
+			}
 		}
 	}
 
-	fmt.Println("Work item successfully updated.")
+	return l
+}
+
+func showChanges(changes []rtc.Change) {
+	fmt.Println("")
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Field", "Old", "New"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetColWidth(appConfig.MaxWidth)
+
+	for _, c := range changes {
+		table.Append([]string{c.Field, c.Old, c.New})
+	}
+	table.Render()
 }
 
-func create(summary string, taskType string, parentId string) {
+func create(wi *rtc.WorkItem, parentId string, descFile string, edit bool) {
 	r, err := login()
 	if err != nil {
 		fmt.Println(err.Error())
 		return
 	}
 
-	wi := &rtc.WorkItem{Summary: summary, Type: taskType}
+	if descFile != "" {
+		var data []byte
+		if descFile == "-" {
+			data, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			data, err = ioutil.ReadFile(descFile)
+		}
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		wi.Description = string(data)
+	}
+
+	if edit {
+		wi.Description, err = EditText(wi.Description + "\n# Write the description for " + wi.Summary + ". Lines starting with # are ignored.\n")
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+	}
+
 	fmt.Printf("Creating %s %s...\n", wi.Type, wi.Summary)
 
 	rwi, err := r.Create(wi)
@@ -754,33 +1500,6 @@ func releases(all bool) {
 	table.Render()
 }
 
-func iterations(all bool) {
-	r, err := login()
-	if err != nil {
-		fmt.Println(err.Error())
-		return
-	}
-
-	iters, err := r.GetIterations()
-	if err != nil {
-		fmt.Println(err.Error())
-		return
-	}
-
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Id", "Iteration"})
-	table.SetAlignment(tablewriter.ALIGN_LEFT)
-	table.SetColWidth(appConfig.MaxWidth)
-
-	for i, iter := range iters {
-		if !all && iter.Completed == "true" {
-			continue
-		}
-		table.Append([]string{strconv.Itoa(i), iter.Label})
-	}
-	table.Render()
-}
-
 func test() {
 
 }
@@ -893,43 +1612,68 @@ func changeset(id string) {
 	// }
 }
 
-func close(id string) {
+func close(c *cli.Context) {
 	r, err := login()
 	if err != nil {
 		fmt.Println(err.Error())
-		return
+		os.Exit(1)
 	}
 
-	err = r.Close(id)
+	ids, _, err := targetIds(r, c, true)
 	if err != nil {
 		fmt.Println(err.Error())
-		return
+		os.Exit(1)
+	}
+
+	runBulk(r, "Closing", ids, func(id string) (string, error) {
+		return "Closed", r.Close(id)
+	})
+}
+
+func move(c *cli.Context) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	ids, args, err := targetIds(r, c, false)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	if len(args) < 1 {
+		fmt.Println("Missing the iteration to move the work items to")
+		os.Exit(1)
 	}
 
-	fmt.Println("Successfully closed work item", id)
+	runBulk(r, "Moving", ids, func(id string) (string, error) {
+		_, iter, err := r.MoveToIteration(id, args[0])
+		return "Moved to " + iter.Label, err
+	})
 }
 
-func move(id string, iterId string) {
+func clone(id string, opts rtc.CloneOptions) {
 	r, err := login()
 	if err != nil {
 		fmt.Println(err.Error())
 		return
 	}
 
-	ids := strings.Split(id, ",")
+	fmt.Printf("Copying work item %s...\n", id)
+	cloned, err := r.Clone(id, opts)
 
-	var iter models.Iteration
+	for _, c := range cloned {
+		fmt.Printf("  %s %s -> %s\n", c.Source.Type, c.Source.Id, c.Copy.Title())
+	}
 
-	for _, i := range ids {
-		fmt.Println("Moving work item " + i + "...")
-		_, iter, err = r.MoveToIteration(i, iterId)
-		if err != nil {
-			fmt.Println(err.Error())
-			return
-		}
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
 	}
 
-	fmt.Println("\nSuccessfully moved work items " + id + " to iteration " + iter.Label)
+	fmt.Printf("\nSuccessfully copied %d work item(s).\n", len(cloned))
 }
 
 func createSubtask(id string, taskType string) {
@@ -940,13 +1684,62 @@ func createSubtask(id string, taskType string) {
 	}
 
 	fmt.Printf("Creating a subtask of type %s...\n", taskType)
-	wi, err := r.CreateSubTask(id, taskType)
+	wi, err := r.CreateSubTask(id, rtc.SubtaskTemplate{Type: taskType})
 	if err != nil {
 		fmt.Println(err.Error())
+		return
 	}
 
 	fmt.Println("Created " + wi.Title())
+}
+
+func createSubtasks(id string, name string) {
+	templates, ok := appConfig.Templates[name]
+	if !ok {
+		names := []string{}
+		for n := range appConfig.Templates {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		fmt.Printf("Unknown template %s. Templates in your config: %s\n", name, strings.Join(names, ", "))
+		return
+	}
+
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	fmt.Printf("Creating the subtasks of template %s...\n\n", name)
+	results, err := r.CreateSubTasks(id, templates)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Type", "Id", "Summary", "Result"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetColWidth(appConfig.MaxWidth)
+
+	failed := 0
+	for _, res := range results {
+		switch {
+		case res.Existing != nil:
+			table.Append([]string{res.Template.Type, res.Existing.Id, res.Existing.Summary, "exists"})
+		case res.Err != nil:
+			failed++
+			table.Append([]string{res.Template.Type, "", "", res.Err.Error()})
+		default:
+			table.Append([]string{res.Template.Type, res.WorkItem.Id, res.WorkItem.Summary, "created"})
+		}
+	}
+	table.Render()
 
+	if failed > 0 {
+		os.Exit(1)
+	}
 }
 
 func reconfig() {
@@ -972,19 +1765,47 @@ func open(id string) {
 }
 
 func query(q Query) {
-	fs := []rtc.Filter{}
-
 	r, err := login()
 	if err != nil {
 		fmt.Println(err.Error())
 		return
 	}
 
-	if err = q.Check(); err != nil {
+	if q.Output != "ids" && q.Output != "csv" {
+		fmt.Println("Querying RTC for work items that match your query...")
+	}
+
+	wis, err := runQuery(r, q)
+	if err != nil {
 		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	if q.Output == "ids" {
+		for _, wi := range wis {
+			fmt.Println(wi.Id)
+		}
+		return
+	}
+
+	if q.Output == "csv" {
+		if err := writeCsv(os.Stdout, wis); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
 		return
 	}
 
+	renderTable(wis)
+}
+
+func runQuery(r *rtc.RTC, q Query) ([]*rtc.WorkItem, error) {
+	fs := []rtc.Filter{}
+
+	if err := q.Check(); err != nil {
+		return nil, err
+	}
+
 	if q.Mine {
 		f := rtc.Filter{
 			Field:  "owner",
@@ -1042,28 +1863,43 @@ func query(q Query) {
 		fs = append(fs, f)
 	}
 
-	if q.Owner != "" {
+	labels := [][]string{
+		{"owner", q.Owner},
+		{"workItemType", q.Type},
+		{"internalPriority", q.Priority},
+		{"internalSeverity", q.Severity},
+		{"category", q.Category},
+		{"target", q.Iteration},
+	}
+
+	for _, l := range labels {
+		if l[1] == "" {
+			continue
+		}
+
+		id, err := r.Lookup(l[0], l[1])
+		if err != nil {
+			return nil, err
+		}
+
 		f := rtc.Filter{
-			Field:  "owner/name",
-			Oper:   "contains",
-			Values: []string{q.Owner},
+			Field:  l[0],
+			Oper:   "is",
+			Values: []string{id},
 		}
 		fs = append(fs, f)
 	}
 
-	fmt.Println("Querying RTC for work items that match your query...")
-	wis, err := r.Query(fs, q.Sort, q.SortAsc, q.MaxResults)
-	if err != nil {
-		fmt.Println(err.Error())
-		return
+	if q.MaxResults <= 0 {
+		return r.QueryAll(fs, q.Sort, q.SortAsc)
 	}
 
-	renderTable(wis)
+	return r.Query(fs, q.Sort, q.SortAsc, q.MaxResults)
 }
 
-func addApproval(id string, desc string, apprName string) {
-	var approver rtc.Owner
-	var owners []rtc.Owner
+func addApproval(id string, desc string, apprNames []string, approvalType string, due string) {
+	var dueDate time.Time
+	var approvers []rtc.Owner
 
 	r, err := login()
 	if err != nil {
@@ -1071,6 +1907,14 @@ func addApproval(id string, desc string, apprName string) {
 		return
 	}
 
+	if due != "" {
+		dueDate, err = time.ParseInLocation("2006-01-02", due, time.Local)
+		if err != nil {
+			fmt.Println("Invalid due date " + due + ". Use a date like 2015-02-28.")
+			return
+		}
+	}
+
 	fmt.Printf("Retrieving work item %s...\n", id)
 	wi, err := r.GetWorkItem(id)
 	if err != nil {
@@ -1078,48 +1922,59 @@ func addApproval(id string, desc string, apprName string) {
 		return
 	}
 
-	if apprName == "" {
-		owners, err = r.GetOwners()
+	c, err := r.GetValueCatalog("task")
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	for _, name := range splitList(apprNames) {
+		ownerId, err := c.Lookup("owner", name)
 		if err != nil {
 			fmt.Println(err.Error())
 			return
 		}
-	} else {
-		all, err := r.GetOwners()
+
+		approvers = append(approvers, rtc.Owner{Id: ownerId, Name: c.Label("owner", ownerId)})
+	}
+
+	if len(approvers) < 1 {
+		owners, err := r.GetOwners()
 		if err != nil {
 			fmt.Println(err.Error())
 			return
 		}
 
-		owners = []rtc.Owner{}
-		for _, o := range all {
-			if strings.Contains(o.Name, apprName) {
-				owners = append(owners, o)
-			}
-		}
-	}
-
-	if len(owners) < 2 {
-		approver = owners[0]
-	} else {
-		fmt.Println("\n")
-
+		fmt.Println("")
 		for i, o := range owners {
 			fmt.Printf("%d. %s\n", i+1, o.Name)
 		}
+		fmt.Println("")
 
-		fmt.Println("\n")
 		app, err := Read("Please select the approver:")
 		if err != nil {
 			fmt.Println(err.Error())
 			return
 		}
+
 		num, err := strconv.Atoi(app)
-		approver = owners[num-1]
+		if err != nil || num < 1 || num > len(owners) {
+			fmt.Println("Invalid approver " + app)
+			return
+		}
+
+		approvers = append(approvers, owners[num-1])
 	}
 
-	fmt.Printf("Adding approval '%s' for %s to %s...\n", desc, approver.Name, wi.Title())
-	err = r.AddApproval(id, desc, approver.Id)
+	ids := []string{}
+	names := []string{}
+	for _, a := range approvers {
+		ids = append(ids, a.Id)
+		names = append(names, a.Name)
+	}
+
+	fmt.Printf("Adding %s '%s' for %s to %s...\n", approvalType, desc, strings.Join(names, ", "), wi.Title())
+	err = r.AddApproval(id, approvalType, desc, dueDate, ids)
 	if err != nil {
 		fmt.Println(err.Error())
 		return
@@ -1127,19 +1982,3 @@ func addApproval(id string, desc string, apprName string) {
 
 	fmt.Println("Approval successfully added")
 }
-
-func approvals(id string) {
-	// r, err := login()
-	// if err != nil {
-	// 	fmt.Println(err.Error())
-	// 	return
-	// }
-
-	// fmt.Printf("Retrieving work item %s...\n", id)
-	// wi, err := r.GetWorkItem(id)
-	// if err != nil {
-	// 	fmt.Println(err.Error())
-	// 	return
-	// }
-
-}
@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"regexp"
 	"strings"
+	"time"
 )
 
 type Envelope struct {
@@ -69,19 +70,54 @@ type Item struct {
 
 type Release struct {
 	Id         string      `xml:"id"`
-	ItemId     string      `xml:"itemid"`
+	ItemId     string      `xml:"itemId"`
 	Label      string      `xml:"label"`
+	StartDate  string      `xml:"startDate"`
+	EndDate    string      `xml:"endDate"`
+	Current    string      `xml:"current"`
 	Completed  string      `xml:"completed"`
 	Archived   string      `xml:"archived"`
 	Iterations []Iteration `xml:"iterations"`
 }
 
+// Start returns the start date of the release, zero if it has none.
+func (r Release) Start() time.Time {
+	return parseDate(r.StartDate)
+}
+
+// End returns the end date of the release, zero if it has none.
+func (r Release) End() time.Time {
+	return parseDate(r.EndDate)
+}
+
+// Iteration is an iteration of a release, such as a sprint, which may have
+// iterations of its own.
 type Iteration struct {
-	Id        string `xml:"id"`
-	ItemId    string `xml:"itemId"`
-	Label     string `xml:"label"`
-	Completed string `xml:"completed"`
-	Archived  string `xml:"archived"`
+	Id           string      `xml:"id"`
+	ItemId       string      `xml:"itemId"`
+	ParentItemId string      `xml:"parentItemId"`
+	Label        string      `xml:"label"`
+	StartDate    string      `xml:"startDate"`
+	EndDate      string      `xml:"endDate"`
+	Current      string      `xml:"current"`
+	Completed    string      `xml:"completed"`
+	Archived     string      `xml:"archived"`
+	Iterations   []Iteration `xml:"iterations"`
+}
+
+// Start returns the start date of the iteration, zero if it has none.
+func (i Iteration) Start() time.Time {
+	return parseDate(i.StartDate)
+}
+
+// End returns the end date of the iteration, zero if it has none.
+func (i Iteration) End() time.Time {
+	return parseDate(i.EndDate)
+}
+
+func parseDate(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
 }
 
 type Value struct {
@@ -99,6 +135,8 @@ type Value struct {
 	Attributes     []*Attribute `xml:"attributes"`
 	LinkTypes      []LinkType   `xml:"linkTypes"`
 	Approvals      []Approval   `xml:"approvals"`
+	History        []Change     `xml:"history"`
+	Queries        []Query      `xml:"queryDescriptors"`
 }
 
 func (val Value) GetAttributes() []*Attribute {
@@ -126,6 +164,23 @@ func (a Approval) State() string {
 	return typeName
 }
 
+// PendingOn returns true if the approval waits for a decision of the user.
+func (a Approval) PendingOn(userItemId string) bool {
+	for _, aa := range a.Approvals {
+		if aa.State() != "pending" {
+			continue
+		}
+
+		for _, ap := range aa.Approvers {
+			if ap.ItemId == userItemId {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 type ApproverApproval struct {
 	TheState  string     `xml:"state"`
 	Approvers []Approver `xml:"approver"`
@@ -166,6 +221,8 @@ type LinkType struct {
 type Link struct {
 	ItemId      string     `xml:"itemId"`
 	StateId     string     `xml:"stateId"`
+	Comment     string     `xml:"comment"`
+	Url         string     `xml:"url"`
 	locationUri string     `xml:"weburi"`
 	Target      LinkTarget `xml:"target"`
 }
@@ -175,12 +232,45 @@ type LinkTarget struct {
 	StateId     string       `xml:"stateId"`
 	LocationUri string       `xml:"locationUri"`
 	Attributes  []*Attribute `xml:"attributes"`
+
+	// attachments
+	Id            string   `xml:"id"`
+	Name          string   `xml:"name"`
+	CreationDate  string   `xml:"creationDate"`
+	ContentType   string   `xml:"contentType"`
+	ContentLength int64    `xml:"contentLength"`
+	Creator       Approver `xml:"creator"`
 }
 
 func (link LinkTarget) GetAttributes() []*Attribute {
 	return link.Attributes
 }
 
+type Change struct {
+	ModifiedBy   Approver          `xml:"modifiedBy"`
+	ModifiedDate string            `xml:"modifiedDate"`
+	Changes      []AttributeChange `xml:"changes"`
+}
+
+type AttributeChange struct {
+	AttributeId   string     `xml:"attributeId"`
+	AttributeName string     `xml:"attributeName"`
+	OldValue      *AttrValue `xml:"oldValue"`
+	NewValue      *AttrValue `xml:"newValue"`
+}
+
+func (v *AttrValue) String() string {
+	if v == nil {
+		return ""
+	}
+
+	if v.Label != "" {
+		return v.Label
+	}
+
+	return v.Content
+}
+
 type Header struct {
 	AttributeId   string `xml:"attributeId"`
 	AttributeType string `xml:"attributeType"`
@@ -192,6 +282,12 @@ type Header struct {
 	LabelOnly     string `xml:"labelOnly"`
 }
 
+type Query struct {
+	ItemId      string `xml:"itemId"`
+	Name        string `xml:"name"`
+	Description string `xml:"description"`
+}
+
 type Row struct {
 	Id          string   `xml:"id"`
 	ItemId      string   `xml:"itemId"`
@@ -226,9 +322,17 @@ type Attribute struct {
 }
 
 type AttrValue struct {
-	Label   string `xml:"label"`
-	Content string `xml:"content"`
-	Id      string `xml:"id"`
+	Label    string    `xml:"label"`
+	Content  string    `xml:"content"`
+	Id       string    `xml:"id"`
+	Comments []Comment `xml:"items"`
+}
+
+type Comment struct {
+	Content      string   `xml:"content"`
+	IsHTML       string   `xml:"isHTML"`
+	CreationDate string   `xml:"creationDate"`
+	Creator      Approver `xml:"creator"`
 }
 
 func NewFromXml(xmlData []byte) (*Envelope, error) {
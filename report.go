@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fcoury/rtc-go/rtc"
+	"github.com/gistia/tablewriter"
+)
+
+// chartWidth is how many characters the longest bar of a chart takes.
+const chartWidth = 50
+
+func bar(d time.Duration, max time.Duration) string {
+	if max <= 0 {
+		return ""
+	}
+
+	return strings.Repeat("#", int(float64(chartWidth)*float64(d)/float64(max)+0.5))
+}
+
+func sprintReport(iterId string, format string) {
+	if format != "chart" && format != "csv" && format != "json" {
+		fmt.Println("Unknown format " + format + ". Use chart, csv or json.")
+		return
+	}
+
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if format == "chart" {
+		fmt.Println("Gathering the work items of the iteration and their history...")
+	}
+
+	report, err := r.GetSprintReport(iterId, true)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(jsonReport(report), "", "  ")
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		fmt.Println(string(data))
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"date", "remainingHours", "idealHours"})
+		for _, p := range report.Burndown {
+			w.Write([]string{p.Date.Local().Format("2006-01-02"), hours(p.Remaining), hours(p.Ideal)})
+		}
+		w.Flush()
+	default:
+		renderSprintReport(report)
+	}
+}
+
+func hours(d time.Duration) string {
+	return strconv.FormatFloat(d.Hours(), 'f', -1, 64)
+}
+
+func renderSprintReport(report *rtc.SprintReport) {
+	iter := report.Iteration
+	fmt.Printf("\n%s  [%s, %s]\n\n", iter.Label, dateRange(iter.Start(), iter.End()), daysLeft(iter.Start(), iter.End(), time.Now()))
+	fmt.Printf("Work items: %d   Estimate: %s   Spent: %s   Remaining: %s\n\n", len(report.Items), formatHours(report.Estimate), formatHours(report.Spent), formatHours(report.Remaining))
+
+	states := []string{}
+	for s := range report.ByState {
+		states = append(states, s)
+	}
+	sort.Strings(states)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"State", "Items"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	for _, s := range states {
+		table.Append([]string{s, strconv.Itoa(report.ByState[s])})
+	}
+	table.Render()
+	fmt.Println("")
+
+	table = tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Owner", "Items", "Estimate", "Spent", "Remaining"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	for _, o := range report.ByOwner {
+		table.Append([]string{valueOrNone(o.Owner), strconv.Itoa(o.Items), formatHours(o.Estimate), formatHours(o.Spent), formatHours(o.Remaining)})
+	}
+	table.Render()
+
+	if len(report.Burndown) < 1 {
+		return
+	}
+
+	var max time.Duration
+	for _, p := range report.Burndown {
+		if p.Remaining > max {
+			max = p.Remaining
+		}
+		if p.Ideal > max {
+			max = p.Ideal
+		}
+	}
+
+	fmt.Println("\nBurndown (# remaining, | ideal):")
+	for _, p := range report.Burndown {
+		line := []byte(fmt.Sprintf("%-*s", chartWidth+1, bar(p.Remaining, max)))
+		if ideal := len(bar(p.Ideal, max)); ideal < len(line) {
+			line[ideal] = '|'
+		}
+		fmt.Printf("  %s %s %s\n", p.Date.Local().Format("01/02"), string(line), formatHours(p.Remaining))
+	}
+}
+
+type ownerJson struct {
+	Owner     string  `json:"owner"`
+	Items     int     `json:"items"`
+	Estimate  float64 `json:"estimateHours"`
+	Spent     float64 `json:"spentHours"`
+	Remaining float64 `json:"remainingHours"`
+}
+
+type burndownJson struct {
+	Date      string  `json:"date"`
+	Remaining float64 `json:"remainingHours"`
+	Ideal     float64 `json:"idealHours"`
+}
+
+type reportJson struct {
+	Iteration string         `json:"iteration"`
+	Start     string         `json:"start"`
+	End       string         `json:"end"`
+	Items     int            `json:"items"`
+	Estimate  float64        `json:"estimateHours"`
+	Spent     float64        `json:"spentHours"`
+	Remaining float64        `json:"remainingHours"`
+	ByState   map[string]int `json:"byState"`
+	ByOwner   []ownerJson    `json:"byOwner"`
+	Burndown  []burndownJson `json:"burndown"`
+}
+
+func jsonReport(report *rtc.SprintReport) *reportJson {
+	j := &reportJson{
+		Iteration: report.Iteration.Label,
+		Start:     report.Iteration.StartDate,
+		End:       report.Iteration.EndDate,
+		Items:     len(report.Items),
+		Estimate:  report.Estimate.Hours(),
+		Spent:     report.Spent.Hours(),
+		Remaining: report.Remaining.Hours(),
+		ByState:   report.ByState,
+		ByOwner:   []ownerJson{},
+		Burndown:  []burndownJson{},
+	}
+
+	for _, o := range report.ByOwner {
+		j.ByOwner = append(j.ByOwner, ownerJson{o.Owner, o.Items, o.Estimate.Hours(), o.Spent.Hours(), o.Remaining.Hours()})
+	}
+
+	for _, p := range report.Burndown {
+		j.Burndown = append(j.Burndown, burndownJson{p.Date.Format(time.RFC3339), p.Remaining.Hours(), p.Ideal.Hours()})
+	}
+
+	return j
+}
+
+func velocityReport(last int) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	fmt.Printf("Gathering the work items closed in the last %d iterations...\n\n", last)
+	velocity, err := r.GetVelocity(last)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	if len(velocity) < 1 {
+		fmt.Println("No iterations ended yet.")
+		return
+	}
+
+	var max, total time.Duration
+	for _, v := range velocity {
+		if v.Completed > max {
+			max = v.Completed
+		}
+		total += v.Completed
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Iteration", "Dates", "Closed", "Completed", ""})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	for _, v := range velocity {
+		table.Append([]string{v.Iteration.Label, dateRange(v.Iteration.Start(), v.Iteration.End()), strconv.Itoa(v.Items), formatHours(v.Completed), bar(v.Completed, max)})
+	}
+	table.Render()
+
+	fmt.Printf("\nAverage: %s per iteration\n", formatHours(total/time.Duration(len(velocity))))
+}
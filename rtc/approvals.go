@@ -0,0 +1,176 @@
+package rtc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fcoury/rtc-go/models"
+)
+
+var ApprovalTypes = []string{"approval", "review", "verification"}
+var ApprovalStates = []string{"approved", "rejected", "pending"}
+
+func checkOneOf(kind string, value string, values []string) error {
+	for _, v := range values {
+		if v == value {
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Invalid %s %s. Use one of: %s", kind, value, strings.Join(values, ", ")))
+}
+
+// AddApproval adds an approval of the given type (approval, review or
+// verification) to the work item, pending on each of the approvers. The due
+// date is optional.
+func (rtc *RTC) AddApproval(id string, approvalType string, desc string, dueDate time.Time, approverIds []string) error {
+	if err := checkOneOf("approval type", approvalType, ApprovalTypes); err != nil {
+		return err
+	}
+
+	if len(approverIds) < 1 {
+		return errors.New("An approval needs at least one approver")
+	}
+
+	approvers := []map[string]string{}
+	for _, a := range approverIds {
+		approvers = append(approvers, map[string]string{
+			"user":  a,
+			"state": "com.ibm.team.workitem.approvalState.pending",
+		})
+	}
+
+	param := map[string]interface{}{
+		"type":      "com.ibm.team.workitem.approvalType." + approvalType,
+		"name":      desc,
+		"approvers": approvers,
+	}
+
+	if !dueDate.IsZero() {
+		param["dueDate"] = strconv.FormatInt(dueDate.UnixNano()/int64(time.Millisecond), 10)
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"cmd": "createApproval", "param": param})
+	if err != nil {
+		return err
+	}
+
+	base, err := rtc.workItemDTO(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = rtc.save(id, base, nil, "", "&updateApprovals="+url.QueryEscape(string(data)))
+	return err
+}
+
+// ListApprovals returns the approvals, reviews and verifications of the work
+// item.
+func (rtc *RTC) ListApprovals(id string) ([]models.Approval, error) {
+	val, err := rtc.workItemDTO(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return val.Approvals, nil
+}
+
+// SetApprovalState approves, rejects or resets to pending the current user's
+// decision on an approval of the work item.
+func (rtc *RTC) SetApprovalState(id string, approvalId string, state string) error {
+	if err := checkOneOf("approval state", state, ApprovalStates); err != nil {
+		return err
+	}
+
+	return rtc.updateApproval(id, approvalId, map[string]interface{}{
+		"cmd": "changeApprovalState",
+		"param": map[string]string{
+			"id":       approvalId,
+			"approver": rtc.OwnerId,
+			"state":    "com.ibm.team.workitem.approvalState." + state,
+		},
+	})
+}
+
+// RemoveApproval deletes an approval from the work item.
+func (rtc *RTC) RemoveApproval(id string, approvalId string) error {
+	return rtc.updateApproval(id, approvalId, map[string]interface{}{
+		"cmd":   "deleteApproval",
+		"param": map[string]string{"id": approvalId},
+	})
+}
+
+func (rtc *RTC) updateApproval(id string, approvalId string, cmd map[string]interface{}) error {
+	base, err := rtc.workItemDTO(id)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, a := range base.Approvals {
+		if a.Id == approvalId {
+			found = true
+		}
+	}
+
+	if !found {
+		return errors.New("No approval " + approvalId + " on work item " + id + ". Use approvals ls.")
+	}
+
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return err
+	}
+
+	_, err = rtc.save(id, base, nil, "", "&updateApprovals="+url.QueryEscape(string(data)))
+	return err
+}
+
+// PendingApproval is an approval on a work item still waiting for a decision
+// of the current user.
+type PendingApproval struct {
+	WorkItem *WorkItem
+	Approval models.Approval
+}
+
+// PendingApprovals returns the approvals of open work items still waiting for
+// the current user.
+func (rtc *RTC) PendingApprovals() ([]PendingApproval, error) {
+	filters := []Filter{
+		{Field: "internalApprovers", Oper: "is", Values: []string{rtc.OwnerId}},
+		openFilter,
+	}
+
+	wis, err := rtc.QueryAll(filters, "modified", false)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, wi := range wis {
+		ids = append(ids, wi.Id)
+	}
+
+	items := make(map[string]*WorkItem)
+	err = rtc.fetchWorkItems(ids, items)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []PendingApproval{}
+	for _, id := range ids {
+		wi := items[id]
+		for _, a := range wi.Approvals {
+			if a.PendingOn(rtc.OwnerId) {
+				pending = append(pending, PendingApproval{WorkItem: wi, Approval: a})
+			}
+		}
+	}
+
+	return pending, nil
+}
@@ -0,0 +1,148 @@
+package rtc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+type Attachment struct {
+	Id          string
+	ItemId      string
+	Name        string
+	ContentType string
+	Size        int64
+	Created     time.Time
+	Author      string
+}
+
+// ListAttachments returns the files attached to the work item.
+func (rtc *RTC) ListAttachments(id string) ([]Attachment, error) {
+	val, err := rtc.workItemDTO(id)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := []Attachment{}
+	for _, lt := range val.LinkTypes {
+		if lt.EndpointId != "attachment" {
+			continue
+		}
+
+		for _, link := range lt.Links {
+			t := link.Target
+			created, _ := time.Parse(time.RFC3339, t.CreationDate)
+			attachments = append(attachments, Attachment{
+				Id:          t.Id,
+				ItemId:      t.ItemId,
+				Name:        t.Name,
+				ContentType: t.ContentType,
+				Size:        t.ContentLength,
+				Created:     created,
+				Author:      t.Creator.Name,
+			})
+		}
+	}
+
+	return attachments, nil
+}
+
+// DownloadAttachment writes the contents of the attachment to w.
+func (rtc *RTC) DownloadAttachment(attachmentId string, w io.Writer) error {
+	downloadUrl := "https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IAttachmentRestService/itemName/com.ibm.team.workitem.Attachment/" + attachmentId
+
+	resp, err := rtc.request("GET", downloadUrl, "")
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return errors.New(fmt.Sprintf("Error: got HTTP status code %d", resp.StatusCode))
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+var uploadedRegexp = regexp.MustCompile(`"itemId"\s*:\s*"([^"]+)"[\s\S]*?"id"\s*:\s*"?(\d+)"?`)
+
+// UploadAttachment uploads the contents of r as a file attached to the work
+// item.
+func (rtc *RTC) UploadAttachment(id string, filename string, r io.Reader) (*Attachment, error) {
+	var body bytes.Buffer
+	name := filepath.Base(filename)
+
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("projectId", projectAreaItemId)
+	mw.WriteField("multiple", "true")
+
+	part, err := mw.CreateFormFile("attach", name)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := io.Copy(part, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = mw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	uploadUrl := "https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.service.internal.rest.IAttachmentRestService/"
+
+	resp, err := rtc.browser.RequestBody("POST", uploadUrl, mw.FormDataContentType(), &body)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, errors.New(fmt.Sprintf("Error: got HTTP status code %d", resp.StatusCode))
+	}
+
+	m := uploadedRegexp.FindStringSubmatch(string(data))
+	if m == nil {
+		return nil, errors.New("Failed to upload " + name + ": unexpected response from server")
+	}
+
+	att := &Attachment{ItemId: m[1], Id: m[2], Name: name, Size: size, Created: time.Now()}
+
+	base, err := rtc.workItemDTO(id)
+	if err != nil {
+		return nil, err
+	}
+
+	lt, err := FindLinkType(base, "attachment")
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := lt.command("addLink", &WorkItem{ItemId: att.ItemId, Summary: att.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	err = rtc.saveLinks(id, base, link)
+	if err != nil {
+		return nil, err
+	}
+
+	return att, nil
+}
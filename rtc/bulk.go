@@ -0,0 +1,64 @@
+package rtc
+
+import (
+	"strings"
+	"sync"
+)
+
+const bulkWorkers = 4
+
+type BulkResult struct {
+	Id      string
+	Message string
+	Err     error
+}
+
+// Bulk runs fn for each of the work item ids on a bounded pool of workers,
+// carrying on when some of them fail. Duplicate ids are run only once and the
+// results are returned in the order the ids were given.
+func (rtc *RTC) Bulk(ids []string, fn func(id string) (string, error)) []BulkResult {
+	results := []BulkResult{}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		results = append(results, BulkResult{Id: id})
+	}
+
+	var wg sync.WaitGroup
+	queue := make(chan int)
+
+	for w := 0; w < bulkWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i].Message, results[i].Err = fn(results[i].Id)
+			}
+		}()
+	}
+
+	for i := range results {
+		queue <- i
+	}
+	close(queue)
+
+	wg.Wait()
+
+	return results
+}
+
+// Failed returns how many of the bulk results are errors.
+func Failed(results []BulkResult) int {
+	n := 0
+	for _, r := range results {
+		if r.Err != nil {
+			n++
+		}
+	}
+
+	return n
+}
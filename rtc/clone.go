@@ -0,0 +1,97 @@
+package rtc
+
+import (
+	"errors"
+	"fmt"
+)
+
+type CloneOptions struct {
+	// Prefix is prepended to the summary of the copies.
+	Prefix string
+
+	// Iteration plans the copies for an iteration instead of none.
+	Iteration string
+
+	// LinkType links each copy to its original, as in "copiedFrom" or
+	// "related". No link is added when empty.
+	LinkType string
+
+	// WithChildren copies the children recursively under the copy.
+	WithChildren bool
+}
+
+type Cloned struct {
+	Source *WorkItem
+	Copy   *WorkItem
+}
+
+// Clone creates a copy of the work item with its type, summary, description,
+// category, tags and estimate, and optionally of its children. It returns the
+// copies made, the one of the work item first.
+func (rtc *RTC) Clone(id string, opts CloneOptions) ([]Cloned, error) {
+	if opts.LinkType != "" {
+		if _, err := FindLinkType(nil, opts.LinkType); err != nil {
+			return nil, err
+		}
+	}
+
+	cloned := []Cloned{}
+	err := rtc.clone(id, "", opts, map[string]bool{}, &cloned)
+	return cloned, err
+}
+
+func (rtc *RTC) clone(id string, parentId string, opts CloneOptions, seen map[string]bool, cloned *[]Cloned) error {
+	if seen[id] {
+		return errors.New("Work item " + id + " is its own ancestor")
+	}
+	seen[id] = true
+
+	src, err := rtc.GetWorkItem(id)
+	if err != nil {
+		return err
+	}
+
+	estimate := ""
+	if src.EstimateTime > 0 {
+		estimate = src.Estimate
+	}
+
+	wi, err := rtc.Create(&WorkItem{
+		Type:         src.Type,
+		Summary:      opts.Prefix + src.Summary,
+		Description:  htmlToText(src.Description),
+		FiledAgainst: src.FiledAgainst,
+		Tags:         src.Tags,
+		Estimate:     estimate,
+		PlannedFor:   opts.Iteration,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to copy %s: %s", id, err.Error())
+	}
+
+	*cloned = append(*cloned, Cloned{Source: src, Copy: wi})
+
+	if parentId != "" {
+		if err := rtc.AddParent(wi.Id, parentId); err != nil {
+			return err
+		}
+	}
+
+	if opts.LinkType != "" {
+		if err := rtc.AddLink(wi.Id, opts.LinkType, src.Id); err != nil {
+			return err
+		}
+	}
+
+	if !opts.WithChildren {
+		return nil
+	}
+
+	for _, c := range src.Children {
+		if err := rtc.clone(c.Id, wi.Id, opts, seen, cloned); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
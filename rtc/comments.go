@@ -0,0 +1,73 @@
+package rtc
+
+import (
+	"encoding/json"
+	"errors"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fcoury/rtc-go/models"
+	"github.com/kennygrant/sanitize"
+)
+
+type Comment struct {
+	Author  string
+	Created time.Time
+	HTML    string
+}
+
+func makeComments(val *models.Value) []Comment {
+	comments := []Comment{}
+
+	attr := val.Attribute("internalComments")
+	if attr == nil {
+		return comments
+	}
+
+	for _, c := range attr.Comments {
+		created, _ := time.Parse(time.RFC3339, c.CreationDate)
+		content := c.Content
+		if c.IsHTML != "true" {
+			content = textToHTML(content)
+		}
+
+		comments = append(comments, Comment{Author: c.Creator.Name, Created: created, HTML: content})
+	}
+
+	return comments
+}
+
+func textToHTML(text string) string {
+	text = html.EscapeString(text)
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	return strings.Replace(text, "\n", "<br/>", -1)
+}
+
+// htmlToText turns the HTML of descriptions and comments back into text.
+func htmlToText(s string) string {
+	s = strings.Replace(s, "<br/>", "\n", -1)
+	s = strings.Replace(s, "<br>", "\n", -1)
+	return strings.TrimSpace(sanitize.HTML(s))
+}
+
+// AddComment posts a plain text comment to the work item.
+func (rtc *RTC) AddComment(id string, text string) error {
+	if strings.TrimSpace(text) == "" {
+		return errors.New("Can't add an empty comment")
+	}
+
+	base, err := rtc.workItemDTO(id)
+	if err != nil {
+		return err
+	}
+
+	cmd, err := json.Marshal(map[string]string{"cmd": "addComment", "content": textToHTML(text)})
+	if err != nil {
+		return err
+	}
+
+	_, err = rtc.save(id, base, nil, "", "&updateComments="+url.QueryEscape(string(cmd)))
+	return err
+}
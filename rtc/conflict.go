@@ -0,0 +1,84 @@
+package rtc
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/fcoury/rtc-go/models"
+)
+
+const maxMergeAttempts = 3
+
+// ErrConflict is returned when a work item is saved based on a state that is
+// no longer current, because someone else changed it in the meantime.
+type ErrConflict struct {
+	Id         string
+	StateId    string
+	Attributes map[string]string
+	Fields     []string
+}
+
+func (e *ErrConflict) Error() string {
+	if len(e.Fields) > 0 {
+		return fmt.Sprintf("Work item %s was changed by someone else. Conflicting fields: %s.", e.Id, strings.Join(e.Fields, ", "))
+	}
+
+	return fmt.Sprintf("Work item %s was changed by someone else. Try again or use --merge.", e.Id)
+}
+
+// postWorkItem posts a save to the work item service, turning stale state
+// rejections into an ErrConflict that carries the newer state.
+func (rtc *RTC) postWorkItem(id string, url string, data string) (*models.Envelope, error) {
+	resp, err := rtc.request("POST", url, data)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusConflict || strings.Contains(string(body), "StaleDataException") {
+		return nil, rtc.newConflict(id)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, errors.New(fmt.Sprintf("Error: got HTTP status code %d", resp.StatusCode))
+	}
+
+	return models.NewFromXml(body)
+}
+
+func (rtc *RTC) newConflict(id string) error {
+	val, err := rtc.workItemDTO(id)
+	if err != nil {
+		return err
+	}
+
+	return &ErrConflict{Id: id, StateId: val.StateId, Attributes: getAttributes(val)}
+}
+
+// conflictingFields returns the attributes that are about to be changed but
+// that someone else already changed since the base state was read.
+func conflictingFields(base *models.Value, theirs map[string]string, attrs map[string]string, action string) []string {
+	ours := getAttributes(base)
+	fields := []string{}
+
+	for _, k := range sortedKeys(attrs) {
+		if ours[k] != theirs[k] {
+			fields = append(fields, k)
+		}
+	}
+
+	if action != "" && ours["internalState"] != theirs["internalState"] {
+		fields = append(fields, "internalState")
+	}
+
+	return fields
+}
@@ -0,0 +1,98 @@
+package rtc
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// dashboardMaxResults is how many work items each dashboard section shows.
+const dashboardMaxResults = 20
+
+// DashboardSection holds the first work items of a dashboard section, and
+// whether there are more.
+type DashboardSection struct {
+	Items []*WorkItem
+	More  bool
+}
+
+type Dashboard struct {
+	InProgress DashboardSection
+	Current    DashboardSection
+	Approvals  []PendingApproval
+	Resolved   DashboardSection
+	Changed    DashboardSection
+
+	// Errors holds what went wrong gathering any of the sections, which are
+	// then left empty.
+	Errors []error
+}
+
+func stateFilter(state string) Filter {
+	return Filter{Field: "internalState", Oper: "is", Values: []string{}, Vars: []map[string]string{{"id": "state", "arguments": state}}}
+}
+
+func afterFilter(field string, t time.Time) Filter {
+	return Filter{Field: field, Oper: "after", Values: []string{strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)}}
+}
+
+// GetDashboard gathers, in parallel, the current user's work items in
+// progress, their open work items in the current iteration, the approvals
+// waiting for them, the work items they created that were resolved since
+// resolvedSince and the ones they are subscribed to that changed since
+// changedSince.
+func (rtc *RTC) GetDashboard(resolvedSince time.Time, changedSince time.Time) *Dashboard {
+	mine := Filter{Field: "owner", Oper: "is", Values: []string{rtc.OwnerId}}
+	current := Filter{Field: "target", Oper: "is", Values: []string{}, Vars: []map[string]string{{"id": "current milestone", "arguments": ""}}}
+
+	d := &Dashboard{}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	query := func(dest *DashboardSection, sortColumn string, filters ...Filter) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// one more than is shown tells whether there are more
+			wis, err := rtc.Query(filters, sortColumn, false, dashboardMaxResults+1)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				d.Errors = append(d.Errors, err)
+				return
+			}
+			if len(wis) > dashboardMaxResults {
+				wis, dest.More = wis[:dashboardMaxResults], true
+			}
+			dest.Items = wis
+		}()
+	}
+
+	query(&d.InProgress, "modified", mine, stateFilter("in progress"))
+	query(&d.Current, "modified", mine, current, stateFilter("open or in progress"))
+	query(&d.Resolved, "resolutionDate",
+		Filter{Field: "creator", Oper: "is", Values: []string{rtc.OwnerId}},
+		stateFilter("closed"),
+		afterFilter("resolutionDate", resolvedSince))
+	query(&d.Changed, "modified",
+		Filter{Field: "internalSubscriptions", Oper: "is", Values: []string{rtc.OwnerId}},
+		afterFilter("modified", changedSince))
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		pending, err := rtc.PendingApprovals()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if err != nil {
+			d.Errors = append(d.Errors, err)
+			return
+		}
+		d.Approvals = pending
+	}()
+
+	wg.Wait()
+	return d
+}
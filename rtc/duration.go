@@ -0,0 +1,125 @@
+package rtc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WorkDay is how long a day is when durations are given in days.
+const WorkDay = 8 * time.Hour
+
+var durationPart = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*([a-z]*)`)
+
+var durationUnits = map[string]time.Duration{
+	"h":       time.Hour,
+	"hr":      time.Hour,
+	"hrs":     time.Hour,
+	"hour":    time.Hour,
+	"hours":   time.Hour,
+	"m":       time.Minute,
+	"min":     time.Minute,
+	"mins":    time.Minute,
+	"minute":  time.Minute,
+	"minutes": time.Minute,
+	"d":       WorkDay,
+	"day":     WorkDay,
+	"days":    WorkDay,
+}
+
+// ParseDuration parses human durations such as "1h30m", "1.5h", "90 min",
+// "2d" or "24 hours", as RTC labels them. A plain number is hours, or minutes
+// when it follows hours, as in "2h30".
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return 0, errors.New("Missing duration")
+	}
+
+	invalid := errors.New("Invalid duration " + s + ". Use something like 1h30m, 1.5h, 90m or 2d.")
+	separator := func(gap string) bool {
+		return strings.Trim(strings.Replace(gap, "and", "", -1), " ,") == ""
+	}
+
+	var d, prev time.Duration
+	last := 0
+	parts := durationPart.FindAllStringSubmatchIndex(s, -1)
+	for i, m := range parts {
+		if !separator(s[last:m[0]]) {
+			return 0, invalid
+		}
+		last = m[1]
+
+		n, _ := strconv.ParseFloat(s[m[2]:m[3]], 64)
+		name := s[m[4]:m[5]]
+
+		unit, ok := durationUnits[name]
+		switch {
+		case ok:
+		case name != "":
+			return 0, invalid
+		case len(parts) == 1:
+			unit = time.Hour
+		case i == len(parts)-1 && prev == time.Hour:
+			unit = time.Minute
+		default:
+			return 0, invalid
+		}
+
+		d += time.Duration(n * float64(unit))
+		prev = unit
+	}
+
+	if len(parts) < 1 || !separator(s[last:]) {
+		return 0, invalid
+	}
+
+	return d, nil
+}
+
+// FormatDuration formats the duration the way RTC labels them, as in
+// "1 hour 30 minutes".
+func FormatDuration(d time.Duration) string {
+	if d <= 0 {
+		return "0 minutes"
+	}
+
+	h := int(d / time.Hour)
+	m := int((d % time.Hour) / time.Minute)
+
+	parts := []string{}
+	if h > 0 {
+		parts = append(parts, plural(h, "hour"))
+	}
+	if m > 0 || h == 0 {
+		parts = append(parts, plural(m, "minute"))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// millis is the value RTC takes for duration attributes.
+func millis(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Millisecond), 10)
+}
+
+// durationValue normalizes a human duration into milliseconds.
+func durationValue(s string) (string, error) {
+	d, err := ParseDuration(s)
+	if err != nil {
+		return "", err
+	}
+
+	return millis(d), nil
+}
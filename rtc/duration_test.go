@@ -0,0 +1,70 @@
+package rtc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		err  bool
+	}{
+		{in: "1h30m", want: 90 * time.Minute},
+		{in: "1.5h", want: 90 * time.Minute},
+		{in: "90 min", want: 90 * time.Minute},
+		{in: "2d", want: 16 * time.Hour},
+		{in: "24 hours", want: 24 * time.Hour},
+		{in: "1 hour 30 minutes", want: 90 * time.Minute},
+		{in: "1 hour and 30 minutes", want: 90 * time.Minute},
+		{in: "1 day, 4 hours", want: 12 * time.Hour},
+		{in: "4", want: 4 * time.Hour},
+		{in: "0.5", want: 30 * time.Minute},
+		{in: " 3H ", want: 3 * time.Hour},
+		{in: "2h30", want: 2*time.Hour + 30*time.Minute},
+		{in: "1d4", err: true},
+		{in: "30m5", err: true},
+		{in: "2 3h", err: true},
+		{in: "", err: true},
+		{in: "soon", err: true},
+		{in: "2 weeks", err: true},
+		{in: "1h and then some", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.in)
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParseDuration(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseDuration(%q) failed: %s", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "0 minutes"},
+		{time.Minute, "1 minute"},
+		{90 * time.Minute, "1 hour 30 minutes"},
+		{24 * time.Hour, "24 hours"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatDuration(tt.in); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package rtc
+
+import (
+	"sort"
+	"time"
+)
+
+type HistoryEntry struct {
+	Author   string    `json:"author"`
+	Modified time.Time `json:"modified"`
+	Changes  []Change  `json:"changes"`
+}
+
+// GetHistory returns the changes made to the work item, oldest first.
+func (rtc *RTC) GetHistory(id string) ([]HistoryEntry, error) {
+	val, err := rtc.fetchWorkItemDTO(id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []HistoryEntry{}
+	for _, h := range val.History {
+		modified, _ := time.Parse(time.RFC3339, h.ModifiedDate)
+		entry := HistoryEntry{Author: h.ModifiedBy.Name, Modified: modified}
+
+		for _, c := range h.Changes {
+			name := c.AttributeName
+			if name == "" {
+				name = c.AttributeId
+			}
+
+			entry.Changes = append(entry.Changes, Change{Attribute: c.AttributeId, Field: name, Old: c.OldValue.String(), New: c.NewValue.String()})
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.Stable(byModified(entries))
+
+	return entries, nil
+}
+
+type byModified []HistoryEntry
+
+func (h byModified) Len() int {
+	return len(h)
+}
+func (h byModified) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+func (h byModified) Less(i, j int) bool {
+	return h[i].Modified.Before(h[j].Modified)
+}
@@ -0,0 +1,243 @@
+package rtc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PlanItem describes a work item to be imported, along with its children.
+// Key identifies it across imports, so that items already created are not
+// created again, and Parent is either the key of another item of the plan or
+// the id of an existing work item.
+type PlanItem struct {
+	Key         string      `yaml:"key"`
+	Parent      string      `yaml:"parent"`
+	Type        string      `yaml:"type"`
+	Summary     string      `yaml:"summary"`
+	Description string      `yaml:"description"`
+	Estimate    string      `yaml:"estimate"`
+	Owner       string      `yaml:"owner"`
+	Iteration   string      `yaml:"iteration"`
+	Category    string      `yaml:"category"`
+	Priority    string      `yaml:"priority"`
+	Severity    string      `yaml:"severity"`
+	Tags        []string    `yaml:"tags"`
+	Children    []*PlanItem `yaml:"children"`
+}
+
+type ImportResult struct {
+	Item   *PlanItem
+	Id     string
+	Status string
+	Err    error
+}
+
+// FlattenPlan returns the items of the plan and their children with parents
+// always before their children. Items without a key get one made of their
+// parent key and summary, and items without a type are tasks.
+func FlattenPlan(items []*PlanItem) ([]*PlanItem, error) {
+	all := []*PlanItem{}
+
+	var walk func(items []*PlanItem, parent *PlanItem)
+	walk = func(items []*PlanItem, parent *PlanItem) {
+		for _, item := range items {
+			if parent != nil {
+				item.Parent = parent.Key
+			}
+			if item.Type == "" {
+				item.Type = "task"
+			}
+			if item.Key == "" {
+				item.Key = item.Summary
+				if item.Parent != "" {
+					item.Key = item.Parent + "/" + item.Summary
+				}
+			}
+			all = append(all, item)
+			walk(item.Children, item)
+		}
+	}
+	walk(items, nil)
+
+	byKey := map[string]*PlanItem{}
+	for _, item := range all {
+		if strings.TrimSpace(item.Summary) == "" {
+			return nil, errors.New("Missing summary for item " + item.Key)
+		}
+		if _, ok := byKey[item.Key]; ok {
+			return nil, errors.New("Duplicate key " + item.Key)
+		}
+		byKey[item.Key] = item
+	}
+
+	// order the items so that the parents given by key come first
+	ordered := []*PlanItem{}
+	done := map[string]bool{}
+	visiting := map[string]bool{}
+
+	var visit func(item *PlanItem) error
+	visit = func(item *PlanItem) error {
+		if done[item.Key] {
+			return nil
+		}
+		if visiting[item.Key] {
+			return errors.New("Item " + item.Key + " is its own ancestor")
+		}
+		visiting[item.Key] = true
+
+		if parent, ok := byKey[item.Parent]; ok {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+
+		done[item.Key] = true
+		ordered = append(ordered, item)
+		return nil
+	}
+
+	for _, item := range all {
+		if err := visit(item); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// ImportState is what an import did so far, so that running it again picks up
+// where it stopped.
+type ImportState struct {
+	// Created maps the keys of the items to the ids of their work items.
+	Created map[string]string `json:"created"`
+
+	// Unlinked maps the keys of created items to the id of the parent they
+	// still have to be linked to.
+	Unlinked map[string]string `json:"unlinked,omitempty"`
+}
+
+func NewImportState() *ImportState {
+	return &ImportState{Created: map[string]string{}, Unlinked: map[string]string{}}
+}
+
+// Import creates the work items of the plan in order and links them to their
+// parents. Items already in the state are skipped, except for retrying the
+// parent links that failed. The state is updated and handed to saved after
+// every change, so nothing is created twice even if the import is cut short.
+// It stops at the first failure, since the items that follow may depend on
+// it. With dryRun nothing is created.
+func (rtc *RTC) Import(items []*PlanItem, state *ImportState, dryRun bool, saved func(*ImportState) error) ([]ImportResult, error) {
+	ordered, err := FlattenPlan(items)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.Created == nil {
+		state.Created = map[string]string{}
+	}
+	if state.Unlinked == nil {
+		state.Unlinked = map[string]string{}
+	}
+
+	keys := map[string]bool{}
+	for _, item := range ordered {
+		keys[item.Key] = true
+	}
+
+	results := []ImportResult{}
+	fail := func(item *PlanItem, id string, err error) ([]ImportResult, error) {
+		results = append(results, ImportResult{Item: item, Id: id, Status: "failed", Err: err})
+		return results, err
+	}
+
+	for _, item := range ordered {
+		if id, ok := state.Created[item.Key]; ok {
+			parentId, unlinked := state.Unlinked[item.Key]
+			if !unlinked {
+				results = append(results, ImportResult{Item: item, Id: id, Status: "exists"})
+				continue
+			}
+
+			if dryRun {
+				results = append(results, ImportResult{Item: item, Id: id, Status: "would link"})
+				continue
+			}
+
+			if err := rtc.linkImported(item, id, parentId, state, saved); err != nil {
+				return fail(item, id, err)
+			}
+			results = append(results, ImportResult{Item: item, Id: id, Status: "linked"})
+			continue
+		}
+
+		parentId := item.Parent
+		if keys[item.Parent] {
+			parentId = state.Created[item.Parent]
+		}
+
+		if dryRun {
+			results = append(results, ImportResult{Item: item, Status: "would create"})
+			continue
+		}
+
+		wi, err := rtc.Create(&WorkItem{
+			Type:         item.Type,
+			Summary:      item.Summary,
+			Description:  item.Description,
+			Estimate:     item.Estimate,
+			OwnedBy:      item.Owner,
+			PlannedFor:   item.Iteration,
+			FiledAgainst: item.Category,
+			Priority:     item.Priority,
+			Severity:     item.Severity,
+			Tags:         item.Tags,
+		})
+		if err != nil {
+			return fail(item, "", fmt.Errorf("Failed to create %s: %s", item.Key, err.Error()))
+		}
+
+		state.Created[item.Key] = wi.Id
+		if parentId != "" {
+			state.Unlinked[item.Key] = parentId
+		}
+		if err := saved(state); err != nil {
+			return fail(item, wi.Id, fmt.Errorf("Created %s as %s but failed to save it: %s", item.Key, wi.Id, err.Error()))
+		}
+
+		if parentId != "" {
+			if err := rtc.linkImported(item, wi.Id, parentId, state, saved); err != nil {
+				return fail(item, wi.Id, err)
+			}
+		}
+
+		results = append(results, ImportResult{Item: item, Id: wi.Id, Status: "created"})
+	}
+
+	return results, nil
+}
+
+// linkImported adds the parent to an imported work item, unless an earlier
+// run already did before being cut short, and records it in the state.
+func (rtc *RTC) linkImported(item *PlanItem, id string, parentId string, state *ImportState, saved func(*ImportState) error) error {
+	wi, err := rtc.GetWorkItem(id)
+	if err != nil {
+		return err
+	}
+
+	linked := false
+	for _, p := range wi.Parents {
+		if p.Id == parentId {
+			linked = true
+		}
+	}
+
+	if !linked {
+		if err := rtc.AddParent(id, parentId); err != nil {
+			return fmt.Errorf("Created %s as %s but failed to add parent %s, run the import again to retry: %s", item.Key, id, parentId, err.Error())
+		}
+	}
+
+	delete(state.Unlinked, item.Key)
+	return saved(state)
+}
@@ -0,0 +1,187 @@
+package rtc
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fcoury/rtc-go/models"
+)
+
+// FindIteration finds an iteration by its item id, its index on the
+// iterations command prefixed with #, as in "#3", the keywords current, next
+// and previous, or its full or partial label, as in "February R1, S1". Labels
+// matching more than one iteration are an error.
+func (rtc *RTC) FindIteration(iterId string) (models.Iteration, error) {
+	var iter models.Iteration
+
+	iterId = strings.TrimSpace(iterId)
+	if iterId == "" {
+		return iter, errors.New("Missing iteration")
+	}
+
+	iters, err := rtc.GetIterations()
+	if err != nil {
+		return iter, err
+	}
+
+	for _, i := range iters {
+		if i.ItemId == iterId {
+			return i, nil
+		}
+	}
+
+	switch strings.ToLower(iterId) {
+	case "current", "next", "previous":
+		return relativeIteration(iters, strings.ToLower(iterId), time.Now())
+	}
+
+	if strings.HasPrefix(iterId, "#") {
+		n, err := strconv.Atoi(strings.TrimPrefix(iterId, "#"))
+		if err != nil || n < 0 || n >= len(iters) {
+			return iter, errors.New("Iteration with index " + iterId + " not found. Use iterations command.")
+		}
+
+		return iters[n], nil
+	}
+
+	matches := matchIterations(iters, iterId)
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+
+	if len(matches) > 1 {
+		labels := []string{}
+		for _, i := range matches {
+			labels = append(labels, i.Label)
+		}
+		return iter, fmt.Errorf("More than one iteration matches \"%s\": %s", iterId, strings.Join(labels, ", "))
+	}
+
+	// the iteration may be a release or something else the catalog knows
+	itemId, err := rtc.Lookup("target", iterId)
+	if err != nil {
+		return iter, err
+	}
+
+	c, err := rtc.GetValueCatalog("task")
+	if err != nil {
+		return iter, err
+	}
+
+	return models.Iteration{ItemId: itemId, Label: c.Label("target", itemId)}, nil
+}
+
+// matchIterations matches the label or id of the iterations exactly, ignoring
+// case, and failing that by containing every word of the label.
+func matchIterations(iters []models.Iteration, label string) []models.Iteration {
+	matches := []models.Iteration{}
+	for _, i := range iters {
+		if strings.EqualFold(i.Label, label) || strings.EqualFold(i.Id, label) {
+			matches = append(matches, i)
+		}
+	}
+
+	if len(matches) > 0 {
+		return matches
+	}
+
+	words := strings.Fields(strings.ToLower(label))
+	for _, i := range iters {
+		l := strings.ToLower(i.Label)
+		found := true
+		for _, w := range words {
+			if !strings.Contains(l, w) {
+				found = false
+				break
+			}
+		}
+		if found {
+			matches = append(matches, i)
+		}
+	}
+
+	return matches
+}
+
+type byStart []models.Iteration
+
+func (s byStart) Len() int           { return len(s) }
+func (s byStart) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byStart) Less(i, j int) bool { return s[i].Start().Before(s[j].Start()) }
+
+// relativeIteration finds the iteration running at the given time, or the
+// one right after or before it, going by their start and end dates. The
+// iterations RTC marks as current are preferred, and when iterations overlap
+// the shortest one wins.
+func relativeIteration(iters []models.Iteration, which string, now time.Time) (models.Iteration, error) {
+	dated := []models.Iteration{}
+	for _, i := range iters {
+		if i.Archived != "true" && !i.Start().IsZero() && !i.End().IsZero() {
+			dated = append(dated, i)
+		}
+	}
+	sort.Stable(byStart(dated))
+
+	current := shortest(dated, func(i models.Iteration) bool {
+		return i.Current == "true"
+	})
+	if current < 0 {
+		current = shortest(dated, func(i models.Iteration) bool {
+			return !now.Before(i.Start()) && now.Before(i.End())
+		})
+	}
+
+	switch which {
+	case "current":
+		if current >= 0 {
+			return dated[current], nil
+		}
+
+	case "next":
+		after := now
+		if current >= 0 {
+			after = dated[current].End()
+		}
+		for _, i := range dated {
+			if !i.Start().Before(after) {
+				return i, nil
+			}
+		}
+
+	case "previous":
+		before := now
+		if current >= 0 {
+			before = dated[current].Start()
+		}
+		for n := len(dated) - 1; n >= 0; n-- {
+			if !dated[n].End().After(before) {
+				return dated[n], nil
+			}
+		}
+	}
+
+	return models.Iteration{}, errors.New("No " + which + " iteration found")
+}
+
+func shortest(iters []models.Iteration, fn func(models.Iteration) bool) int {
+	found := -1
+	for n, i := range iters {
+		if !fn(i) {
+			continue
+		}
+		if found < 0 || i.End().Sub(i.Start()) < iters[found].End().Sub(iters[found].Start()) {
+			found = n
+		}
+	}
+
+	return found
+}
+
+// CurrentIteration returns the iteration running now.
+func (rtc *RTC) CurrentIteration() (models.Iteration, error) {
+	return rtc.FindIteration("current")
+}
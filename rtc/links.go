@@ -0,0 +1,252 @@
+package rtc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/fcoury/rtc-go/models"
+)
+
+type LinkType struct {
+	Endpoint string
+	Id       string
+	IsSource bool
+	Name     string
+}
+
+// LinkTypes are the work item link endpoints that can be added and removed.
+// The link types reported for the work item itself take precedence.
+var LinkTypes = []LinkType{
+	{"parent", "com.ibm.team.workitem.linktype.parentworkitem", false, "Parent"},
+	{"children", "com.ibm.team.workitem.linktype.parentworkitem", true, "Children"},
+	{"blocks", "com.ibm.team.workitem.linktype.blocksworkitem", false, "Blocks"},
+	{"dependsOn", "com.ibm.team.workitem.linktype.blocksworkitem", true, "Depends On"},
+	{"related", "com.ibm.team.workitem.linktype.relatedworkitem", false, "Related"},
+	{"duplicateOf", "com.ibm.team.workitem.linktype.duplicateworkitem", false, "Duplicate Of"},
+	{"duplicates", "com.ibm.team.workitem.linktype.duplicateworkitem", true, "Duplicated By"},
+	{"tracks", "com.ibm.team.workitem.linktype.tracksworkitem", false, "Tracks"},
+	{"contributesTo", "com.ibm.team.workitem.linktype.tracksworkitem", true, "Contributes To"},
+	{"copiedFrom", "com.ibm.team.workitem.linktype.copiedworkitem", false, "Copied From"},
+	{"copies", "com.ibm.team.workitem.linktype.copiedworkitem", true, "Copies"},
+	{"attachment", "com.ibm.team.workitem.linktype.attachment", false, "Attachments"},
+}
+
+func normalizeLinkName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.Replace(name, "-", "", -1)
+	name = strings.Replace(name, "_", "", -1)
+	return strings.Replace(name, " ", "", -1)
+}
+
+// FindLinkType finds the link type by its endpoint id or name, as in
+// "dependsOn", "depends-on" or "Depends On", preferring the link types
+// reported by the work item.
+func FindLinkType(val *models.Value, name string) (LinkType, error) {
+	n := normalizeLinkName(name)
+
+	if val != nil {
+		for _, lt := range val.LinkTypes {
+			if normalizeLinkName(lt.EndpointId) == n || normalizeLinkName(lt.DisplayName) == n {
+				return LinkType{Endpoint: lt.EndpointId, Id: lt.Id, IsSource: lt.IsSource == "true", Name: lt.DisplayName}, nil
+			}
+		}
+	}
+
+	for _, lt := range LinkTypes {
+		if normalizeLinkName(lt.Endpoint) == n || normalizeLinkName(lt.Name) == n {
+			return lt, nil
+		}
+	}
+
+	names := []string{}
+	for _, lt := range LinkTypes {
+		names = append(names, lt.Endpoint)
+	}
+
+	return LinkType{}, errors.New("Unknown link type " + name + ". Use one of: " + strings.Join(names, ", "))
+}
+
+func (lt LinkType) end() string {
+	if lt.IsSource {
+		return "source"
+	}
+
+	return "target"
+}
+
+func (lt LinkType) command(cmd string, target *WorkItem) (string, error) {
+	m := map[string]string{
+		"cmd":    cmd,
+		"type":   lt.Id,
+		"end":    lt.end(),
+		"name":   lt.Name,
+		"itemId": target.ItemId,
+	}
+
+	if cmd == "addLink" {
+		m["comment"] = target.Summary
+		if target.Id != "" {
+			m["comment"] = fmt.Sprintf("%s: %s", target.Id, target.Summary)
+		}
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// AddLink links the work item to the target work item using the link type
+// endpoint given, such as "blocks", "related" or "tracks".
+func (rtc *RTC) AddLink(id string, linkType string, targetId string) error {
+	return rtc.updateLink(id, "addLink", linkType, targetId)
+}
+
+// RemoveLink removes the link of the given type between the work item and the
+// target work item.
+func (rtc *RTC) RemoveLink(id string, linkType string, targetId string) error {
+	return rtc.updateLink(id, "removeLink", linkType, targetId)
+}
+
+func (rtc *RTC) updateLink(id string, cmd string, linkType string, targetId string) error {
+	base, err := rtc.workItemDTO(id)
+	if err != nil {
+		return err
+	}
+
+	lt, err := FindLinkType(base, linkType)
+	if err != nil {
+		return err
+	}
+
+	target, err := rtc.Retrieve(targetId)
+	if err != nil {
+		return err
+	}
+
+	link, err := lt.command(cmd, target)
+	if err != nil {
+		return err
+	}
+
+	return rtc.saveLinks(id, base, link)
+}
+
+// saveLinks applies all the link commands to the work item in a single save.
+func (rtc *RTC) saveLinks(id string, base *models.Value, links ...string) error {
+	extra := ""
+	for _, l := range links {
+		extra += "&updateLinks=" + url.QueryEscape(l)
+	}
+
+	_, err := rtc.save(id, base, nil, "", extra)
+	return err
+}
+
+func linksOf(val *models.Value, endpoint string) []Reference {
+	refs := []Reference{}
+	for _, lt := range val.LinkTypes {
+		if lt.EndpointId != endpoint {
+			continue
+		}
+
+		for _, link := range lt.Links {
+			refs = append(refs, makeRef(lt, link))
+		}
+	}
+
+	return refs
+}
+
+func removeLinkCommands(lt LinkType, refs []Reference) ([]string, error) {
+	links := []string{}
+	for _, ref := range refs {
+		link, err := lt.command("removeLink", &WorkItem{ItemId: ref.ItemId, Id: ref.Id, Summary: ref.Summary})
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+// AddParent adds a parent to a work item that has none yet.
+func (rtc *RTC) AddParent(id string, parentId string) error {
+	base, err := rtc.workItemDTO(id)
+	if err != nil {
+		return err
+	}
+
+	if parents := linksOf(base, "parent"); len(parents) > 0 {
+		return errors.New(fmt.Sprintf("Work item %s already has parent %s. Use reparent to change it.", id, parents[0].Id))
+	}
+
+	return rtc.updateLink(id, "addLink", "parent", parentId)
+}
+
+// RemoveParent unlinks the work item from its parent.
+func (rtc *RTC) RemoveParent(id string) error {
+	base, err := rtc.workItemDTO(id)
+	if err != nil {
+		return err
+	}
+
+	parents := linksOf(base, "parent")
+	if len(parents) < 1 {
+		return errors.New("Work item " + id + " has no parent")
+	}
+
+	lt, err := FindLinkType(base, "parent")
+	if err != nil {
+		return err
+	}
+
+	links, err := removeLinkCommands(lt, parents)
+	if err != nil {
+		return err
+	}
+
+	return rtc.saveLinks(id, base, links...)
+}
+
+// SetParent replaces the parent of the work item, if any, with the given one
+// in a single save.
+func (rtc *RTC) SetParent(id string, parentId string) error {
+	base, err := rtc.workItemDTO(id)
+	if err != nil {
+		return err
+	}
+
+	lt, err := FindLinkType(base, "parent")
+	if err != nil {
+		return err
+	}
+
+	parents := linksOf(base, "parent")
+	if len(parents) == 1 && parents[0].Id == parentId {
+		return nil
+	}
+
+	parent, err := rtc.Retrieve(parentId)
+	if err != nil {
+		return err
+	}
+
+	links, err := removeLinkCommands(lt, parents)
+	if err != nil {
+		return err
+	}
+
+	link, err := lt.command("addLink", parent)
+	if err != nil {
+		return err
+	}
+
+	return rtc.saveLinks(id, base, append(links, link)...)
+}
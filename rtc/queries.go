@@ -0,0 +1,54 @@
+package rtc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SavedQuery is a query saved in the project area.
+type SavedQuery struct {
+	ItemId      string
+	Name        string
+	Description string
+}
+
+type byQueryName []SavedQuery
+
+func (s byQueryName) Len() int      { return len(s) }
+func (s byQueryName) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byQueryName) Less(i, j int) bool {
+	return strings.ToLower(s[i].Name) < strings.ToLower(s[j].Name)
+}
+
+// SavedQueries lists the queries saved in the project area that the user can
+// run, sorted by name.
+func (rtc *RTC) SavedQueries() ([]SavedQuery, error) {
+	url := "https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IQueryRestService/queryDescriptors?projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ&scope=all"
+
+	env, err := rtc.requestXml("GET", url, "")
+	if err != nil {
+		return nil, err
+	}
+
+	queries := []SavedQuery{}
+	for _, q := range env.Body.Response.ReturnValue.Value.Queries {
+		queries = append(queries, SavedQuery{q.ItemId, q.Name, q.Description})
+	}
+	sort.Sort(byQueryName(queries))
+
+	return queries, nil
+}
+
+// RunSavedQuery returns the work items of the saved query with the given item
+// id, at most maxResults of them.
+func (rtc *RTC) RunSavedQuery(itemId string, maxResults int) ([]*WorkItem, error) {
+	queryUrl := fmt.Sprintf("https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IQueryRestService/getResultSet?startIndex=0&maxResults=%d&absoluteURIs=true&projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ&columnIdentifiers=workItemType&columnIdentifiers=summary&columnIdentifiers=creator&columnIdentifiers=owner&columnIdentifiers=creationDate&columnIdentifiers=duration&columnIdentifiers=category&columnIdentifiers=target&columnIdentifiers=projectArea&columnIdentifiers=internalTags&columnIdentifiers=internalState&itemId=%s&skipOAuth=true&filterAttribute=&filterValue=", maxResults, itemId)
+
+	env, err := rtc.requestXml("POST", queryUrl, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return workItemsFromRows(env.Body.Response.ReturnValue.Value.Rows), nil
+}
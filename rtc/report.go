@@ -0,0 +1,339 @@
+package rtc
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fcoury/rtc-go/models"
+)
+
+type OwnerStats struct {
+	Owner     string
+	Items     int
+	Estimate  time.Duration
+	Spent     time.Duration
+	Remaining time.Duration
+}
+
+type BurndownPoint struct {
+	Date      time.Time
+	Remaining time.Duration
+	Ideal     time.Duration
+}
+
+type SprintReport struct {
+	Iteration models.Iteration
+	Items     []*WorkItem
+	Estimate  time.Duration
+	Spent     time.Duration
+	Remaining time.Duration
+	ByState   map[string]int
+	ByOwner   []*OwnerStats
+	Burndown  []BurndownPoint
+}
+
+type Velocity struct {
+	Iteration models.Iteration
+	Items     int
+	Completed time.Duration
+}
+
+func iterationFilter(itemId string) Filter {
+	return Filter{Field: "target", Oper: "is", Values: []string{itemId}}
+}
+
+var closedFilter = stateFilter("closed")
+
+// closedStatesSample is how many recently closed work items of the project
+// area the names of the closed states are learnt from.
+const closedStatesSample = 200
+
+// remaining is the work left on an item: nothing once it's closed, otherwise
+// what is left of its estimate.
+func remaining(estimate time.Duration, spent time.Duration, closed bool) time.Duration {
+	if closed || spent >= estimate {
+		return 0
+	}
+
+	return estimate - spent
+}
+
+// GetSprintReport summarises the work items planned for the iteration: their
+// estimates against the time spent, by state and by owner, and, with
+// burndown, the work remaining at the end of each day of the iteration as
+// rebuilt from their history.
+func (rtc *RTC) GetSprintReport(iterId string, burndown bool) (*SprintReport, error) {
+	iter, err := rtc.FindIteration(iterId)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := rtc.QueryAll([]Filter{iterationFilter(iter.ItemId)}, "id", true)
+	if err != nil {
+		return nil, err
+	}
+
+	closed, err := rtc.QueryAll([]Filter{iterationFilter(iter.ItemId), closedFilter}, "id", true)
+	if err != nil {
+		return nil, err
+	}
+
+	isClosed := map[string]bool{}
+	for _, wi := range closed {
+		isClosed[wi.Id] = true
+	}
+
+	ids := []string{}
+	for _, wi := range found {
+		ids = append(ids, wi.Id)
+	}
+
+	items := make(map[string]*WorkItem)
+	if err := rtc.fetchWorkItems(ids, items); err != nil {
+		return nil, err
+	}
+
+	report := &SprintReport{Iteration: iter, ByState: map[string]int{}}
+	owners := map[string]*OwnerStats{}
+
+	for _, id := range ids {
+		wi := items[id]
+		left := remaining(wi.EstimateTime, wi.SpentTime, isClosed[id])
+
+		report.Items = append(report.Items, wi)
+		report.Estimate += wi.EstimateTime
+		report.Spent += wi.SpentTime
+		report.Remaining += left
+		report.ByState[wi.State]++
+
+		o, ok := owners[wi.OwnedBy]
+		if !ok {
+			o = &OwnerStats{Owner: wi.OwnedBy}
+			owners[wi.OwnedBy] = o
+			report.ByOwner = append(report.ByOwner, o)
+		}
+		o.Items++
+		o.Estimate += wi.EstimateTime
+		o.Spent += wi.SpentTime
+		o.Remaining += left
+	}
+
+	sort.Sort(byRemaining(report.ByOwner))
+
+	if burndown {
+		report.Burndown, err = rtc.burndown(iter, report.Items, isClosed)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+type byRemaining []*OwnerStats
+
+func (o byRemaining) Len() int {
+	return len(o)
+}
+func (o byRemaining) Swap(i, j int) {
+	o[i], o[j] = o[j], o[i]
+}
+func (o byRemaining) Less(i, j int) bool {
+	return o[i].Remaining > o[j].Remaining
+}
+
+// closedStates returns the names of the states in the closed group. Workflows
+// don't tell, so they are learnt from the work items closed now, including
+// recently closed ones of the whole project area, to know the closed states
+// of items that were reopened since.
+func (rtc *RTC) closedStates(items []*WorkItem, isClosed map[string]bool) (map[string]bool, error) {
+	states := map[string]bool{}
+	for _, wi := range items {
+		if isClosed[wi.Id] {
+			states[wi.State] = true
+		}
+	}
+
+	recent, err := rtc.Query([]Filter{closedFilter}, "modified", false, closedStatesSample)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, wi := range recent {
+		states[wi.State] = true
+	}
+
+	return states, nil
+}
+
+func (rtc *RTC) burndown(iter models.Iteration, items []*WorkItem, isClosed map[string]bool) ([]BurndownPoint, error) {
+	start, end := iter.Start(), iter.End()
+	if start.IsZero() || end.IsZero() {
+		return nil, nil
+	}
+
+	closedStates, err := rtc.closedStates(items, isClosed)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, wi := range items {
+		ids = append(ids, wi.Id)
+	}
+
+	var mutex sync.Mutex
+	histories := map[string][]HistoryEntry{}
+	results := rtc.Bulk(ids, func(id string) (string, error) {
+		h, err := rtc.GetHistory(id)
+		mutex.Lock()
+		histories[id] = h
+		mutex.Unlock()
+		return "", err
+	})
+
+	for _, res := range results {
+		if res.Err != nil {
+			return nil, res.Err
+		}
+	}
+
+	remainingOn := func(t time.Time) time.Duration {
+		var d time.Duration
+		for _, wi := range items {
+			d += remainingAt(wi, histories[wi.Id], t, isClosed[wi.Id], closedStates)
+		}
+		return d
+	}
+
+	// one point at the start and one at the end of each day so far
+	total := remainingOn(start)
+	length := end.Sub(start)
+	now := time.Now()
+
+	points := []BurndownPoint{{Date: start, Remaining: total, Ideal: total}}
+	for day := start.AddDate(0, 0, 1); day.Before(end.AddDate(0, 0, 1)) && !day.After(now); day = day.AddDate(0, 0, 1) {
+		if day.After(end) {
+			day = end
+		}
+
+		ideal := time.Duration(float64(total) * (1 - float64(day.Sub(start))/float64(length)))
+		points = append(points, BurndownPoint{Date: day, Remaining: remainingOn(day), Ideal: ideal})
+	}
+
+	return points, nil
+}
+
+// remainingAt rebuilds the work remaining on the item at the given time from
+// its current values and its history.
+func remainingAt(wi *WorkItem, history []HistoryEntry, t time.Time, closed bool, closedStates map[string]bool) time.Duration {
+	estimate := wi.EstimateTime
+	if v, ok := valueAt(history, "duration", t); ok {
+		estimate = historyDuration(v)
+	}
+
+	spent := wi.SpentTime
+	if v, ok := valueAt(history, "timeSpent", t); ok {
+		spent = historyDuration(v)
+	}
+
+	if v, ok := valueAt(history, "internalState", t); ok {
+		closed = closedStates[v]
+	}
+
+	return remaining(estimate, spent, closed)
+}
+
+// valueAt returns the value the attribute had at the given time according to
+// the history, and false when it didn't change since, so the current value
+// holds.
+func valueAt(history []HistoryEntry, attr string, t time.Time) (string, bool) {
+	value, found := "", false
+	for n := len(history) - 1; n >= 0; n-- {
+		for _, c := range history[n].Changes {
+			if c.Attribute != attr {
+				continue
+			}
+
+			if !history[n].Modified.After(t) {
+				return c.New, true
+			}
+			value, found = c.Old, true
+		}
+	}
+
+	return value, found
+}
+
+// historyDuration parses a duration from the history, which carries either
+// the RTC label or the milliseconds.
+func historyDuration(s string) time.Duration {
+	if s == "" {
+		return 0
+	}
+
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if ms < 0 {
+			return 0
+		}
+		return time.Duration(ms) * time.Millisecond
+	}
+
+	d, err := ParseDuration(s)
+	if err != nil {
+		return 0
+	}
+
+	return d
+}
+
+// GetVelocity returns the estimate of the work items closed in each of the
+// last iterations that ended, oldest first.
+func (rtc *RTC) GetVelocity(last int) ([]Velocity, error) {
+	iters, err := rtc.GetIterations()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ended := []models.Iteration{}
+	for _, i := range iters {
+		if len(i.Iterations) == 0 && !i.End().IsZero() && i.End().Before(now) {
+			ended = append(ended, i)
+		}
+	}
+
+	sort.Stable(byStart(ended))
+	if len(ended) > last {
+		ended = ended[len(ended)-last:]
+	}
+
+	velocity := []Velocity{}
+	for _, i := range ended {
+		wis, err := rtc.QueryAll([]Filter{iterationFilter(i.ItemId), closedFilter}, "id", true)
+		if err != nil {
+			return nil, err
+		}
+
+		// the estimates of the work items, as the sprint report takes them
+		ids := []string{}
+		for _, wi := range wis {
+			ids = append(ids, wi.Id)
+		}
+
+		items := make(map[string]*WorkItem)
+		if err := rtc.fetchWorkItems(ids, items); err != nil {
+			return nil, err
+		}
+
+		v := Velocity{Iteration: i, Items: len(wis)}
+		for _, id := range ids {
+			v.Completed += items[id].EstimateTime
+		}
+		velocity = append(velocity, v)
+	}
+
+	return velocity, nil
+}
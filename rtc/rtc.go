@@ -10,6 +10,8 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/fcoury/rtc-go/browser"
 	"github.com/fcoury/rtc-go/models"
@@ -22,7 +24,17 @@ type RTC struct {
 
 	OwnerId string
 
-	browser *browser.Browser
+	// Merge makes saves that conflict with changes made by someone else be
+	// retried on top of those changes, as long as they touch other attributes.
+	Merge bool
+
+	// Defaults holds the attribute values set on new work items when they
+	// aren't given, keyed by work item type, or "*" for every type.
+	Defaults map[string]map[string]string
+
+	browser  *browser.Browser
+	catalogs map[string]*ValueCatalog
+	mutex    sync.Mutex
 }
 
 type WorkItem struct {
@@ -35,6 +47,8 @@ type WorkItem struct {
 	OwnedBy      string
 	Estimate     string
 	TimeSpent    string
+	EstimateTime time.Duration
+	SpentTime    time.Duration
 	FiledAgainst string
 	PlannedFor   string
 	LocationUri  string
@@ -46,7 +60,12 @@ type WorkItem struct {
 	Iteration    Iteration
 	Parents      []Reference
 	Children     []Reference
+	Links        map[string][]Reference
+	Priority     string
+	Severity     string
+	Tags         []string
 	Approvals    []models.Approval
+	Comments     []Comment
 }
 
 type Reference struct {
@@ -56,6 +75,7 @@ type Reference struct {
 	Summary     string
 	Type        string
 	Id          string
+	LinkType    string
 }
 
 type Release struct {
@@ -97,7 +117,7 @@ func (wi *WorkItem) Title() string {
 }
 
 func (wi *WorkItem) Owner() string {
-	parts := strings.Split(wi.OwnedBy, " ")
+	parts := strings.Fields(wi.OwnedBy)
 	res := ""
 	for _, c := range parts {
 		res = res + string(c[0])
@@ -165,62 +185,11 @@ func (rtc *RTC) Login() error {
 	return nil
 }
 
-func (rtc *RTC) CurrentWorkItems() ([]*WorkItem, error) {
-	var workItems []*WorkItem
-
-	queryUrl := "https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IQueryRestService/getResultSet?startIndex=0&maxResults=100&absoluteURIs=true&projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ&columnIdentifiers=workItemType&columnIdentifiers=summary&columnIdentifiers=creator&columnIdentifiers=owner&columnIdentifiers=creationDate&columnIdentifiers=duration&columnIdentifiers=category&columnIdentifiers=target&columnIdentifiers=projectArea&columnIdentifiers=internalTags&columnIdentifiers=internalState&itemId=_VMvycVRcEd61fuNW84kdiQ&skipOAuth=true&filterAttribute=&filterValue="
-
-	resp, err := rtc.request("POST", queryUrl, "")
-	if err != nil {
-		return workItems, err
-	}
-
-	body, err := ioutil.ReadAll(resp.Body)
-
-	// fmt.Println("Body: ", string(body))
-
-	if err != nil {
-		return workItems, err
-	}
-
-	env, err := models.NewFromXml(body)
-
-	if err != nil {
-		return workItems, err
-	}
-
-	for _, row := range env.Body.Response.ReturnValue.Value.Rows {
-		// fmt.Printf("Row: %+v\n", row)
-		// for i, l := range row.Labels {
-		// 	fmt.Printf("%d - %s\n", i, l)
-		// }
-		// fmt.Printf("Item: %d - %s\n", row.Id, row.Labels[1])
-		// 0 - Task
-		// 1 - Analysis: Component elimination. Removal of order component failed.
-		// 2 - Marcelo De Campos
-		// 3 - Felipe Gonçalves Coury
-		// 4 - 1374758469269
-		// 5 - 24 hours
-		// 6 - Unassigned
-		// 7 - [2014] February R1, S1
-		// 8 - SD-OPS
-		// 9 -
-		wi := &WorkItem{
-			Id:           row.Id,
-			Type:         row.Labels[0],
-			Summary:      row.Labels[1],
-			CreatedBy:    row.Labels[2],
-			OwnedBy:      row.Labels[3],
-			Estimate:     row.Labels[5],
-			FiledAgainst: row.Labels[6],
-			PlannedFor:   row.Labels[7],
-			State:        row.Labels[10],
-			LocationUri:  row.LocationUri,
-		}
-		workItems = append(workItems, wi)
-	}
+// MyWorkItemsQuery is the saved query CurrentWorkItems runs.
+const MyWorkItemsQuery = "_VMvycVRcEd61fuNW84kdiQ"
 
-	return workItems, err
+func (rtc *RTC) CurrentWorkItems() ([]*WorkItem, error) {
+	return rtc.RunSavedQuery(MyWorkItemsQuery, 100)
 }
 
 type Filter struct {
@@ -231,6 +200,31 @@ type Filter struct {
 }
 
 func (rtc *RTC) Query(filters []Filter, sortColumn string, sortAscending bool, maxResults int) ([]*WorkItem, error) {
+	return rtc.queryPage(filters, sortColumn, sortAscending, 0, maxResults)
+}
+
+// queryPageSize is how many work items QueryAll fetches at a time.
+const queryPageSize = 500
+
+// QueryAll returns every work item matching the filters, fetching them a page
+// at a time.
+func (rtc *RTC) QueryAll(filters []Filter, sortColumn string, sortAscending bool) ([]*WorkItem, error) {
+	var workItems []*WorkItem
+
+	for {
+		page, err := rtc.queryPage(filters, sortColumn, sortAscending, len(workItems), queryPageSize)
+		if err != nil {
+			return workItems, err
+		}
+
+		workItems = append(workItems, page...)
+		if len(page) < queryPageSize {
+			return workItems, nil
+		}
+	}
+}
+
+func (rtc *RTC) queryPage(filters []Filter, sortColumn string, sortAscending bool, startIndex int, maxResults int) ([]*WorkItem, error) {
 	// curl "https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IQueryRestService/getResultSet" -H "Cookie: com_ibm_team_process_web_ui_internal_admin_projects_ProcessTree_0SaveSelectedCookie="%"2F0; JazzFormAuth=Form; net-jazz-ajax-cookie-rememberUserId=; ibmSurvey=1422910922008; UnicaNIODID=r2adbtayyw2-ZDKlNvR; pSite=https"%"3A"%"2F"%"2Fwww.ibm.com"%"2Fdeveloperworks"%"2Ftopics"%"2Frest"%"2520api"%"2520"%"2520python"%"2F; mmcore.tst=0.911; mmid=-1314913985"%"7CAQAAAAo69LY+igsAAA"%"3D"%"3D; mmcore.pd=1780648624"%"7CAQAAAAoBQjr0tj6KC46Z2xgBAHt7sKJCDdJIEXd3dy5nb29nbGUuY29tLmJyDgAAAHt7sKJCDdJIAAAAAP////8AGQAAAP////8AEXd3dy5nb29nbGUuY29tLmJyBIoLAQAAAAAAAwAAAAAA////////////////AAAAAAABRQ"%"3D"%"3D; mmcore.srv=nycvwcgus02; CoreID6=79140352120814229109241&ci=50200000|DEVWRKS; CoreM_State=73~-1~-1~-1~-1~3~3~5~3~3~7~7~|~~|~~|~~|~||||||~|~~|~~|~~|~~|~~|~~|~~|~; CoreM_State_Content=6~|~~|~|; 50200000_clogin=v=1&l=1422910924&e=1422912724704; LtpaToken2=QT7AQ2NxDXkcEUJx0//EbS+Ta+y6IlVedjbU0yZvHSvf+W4Sxc7+s6iWWFrxE4hRkyvLTH7vrK3YQBJDUMVJSfDpv3v2AgOerm1oy/Vufc4fadGZdYiAdmIAwPIYnQpUIh30eY0EiSsXtPmxTbaOWEaniuAB5FeVy6SkYV/Ud6y2XR5UeXt0VuO+fcNNQM0ClosAE4Y3w9HgMGacuRfN3vNvh05yN87J3COyBb2m9RNcjpz0iY+YsaRxwJ7lZMPI3B5F+h9AREu5THQkczrcmVoUVwbB9bKdnIltP+nibQET5UXEzAh33tKaeKJ6Ivc3X2WkeIcxUHG1QCXTo1Jp8/uqUlaB+Fpl7TpnyLm7eFucKa3SqFiLA2Q3bsw+Cuuw95BWKsZmaHzc9bS+CJwevKREyAo2gcZMLsxouwl5daWm6LJkpvv1fLXfeOKiioNnuuA38262GLRCSVLsNYZatuuN00TRdzFQyjkYcH5uO5hHu0Od3mq+N+D8PfzWL4mrH9MrAi4CBf58mNA0NTri127jigDOcqRYdG8VZMOs+NLHxQGfJmZQZ3oQcrWP3phL3JrLKlb32OKu3tKDN2nxhR2ppiyKtK3uTVUOer5c0sbI6HUOtawD+VzyxHivgPLZg3sfwCZqD3+Z3uKd6KjalCFWPwqXKei3R3Zs1SjgXME=; JSESSIONID=0000w0b_QruvkcrpBiUBwiiWDew:-1" -H "X-jazz-downstream-auth-client-level: 4.0" -H "Origin: https://igartc01.swg.usma.ibm.com" -H "Accept-Encoding: gzip, deflate" -H "Accept-Language: en-US,en;q=0.8" -H "X-com-ibm-team-configuration-versions: LATEST" -H "User-Agent: Mozilla/5.0 (Windows NT 6.1; WOW64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/40.0.2214.93 Safari/537.36" -H "Content-Type: application/x-www-form-urlencoded; charset=UTF-8" -H "accept: text/json" -H "Referer: https://igartc01.swg.usma.ibm.com/jazz/web/projects/SD-OPS" -H "X-Requested-With: XMLHttpRequest" -H "Connection: keep-alive" --data "startIndex=0&maxResults=50&filterAttribute=&filterValue=&columnIdentifiers=workItemType&columnIdentifiers=id&columnIdentifiers=summary&columnIdentifiers=owner&columnIdentifiers=internalState&columnIdentifiers=internalPriority&columnIdentifiers=internalSeverity&columnIdentifiers=modified&sortColumns=modified&sortDirections=false&projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ&jsonExpression="%"7B"%"22operator"%"22"%"3A"%"22AND"%"22"%"2C"%"22attributeExpressions"%"22"%"3A"%"5B"%"7B"%"22attributeId"%"22"%"3A"%"22owner"%"22"%"2C"%"22operator"%"22"%"3A"%"22is"%"22"%"2C"%"22values"%"22"%"3A"%"5B"%"22_PrOIoMZ5Ed-Lr-wDR3V_pA"%"22"%"5D"%"2C"%"22variables"%"22"%"3A"%"5B"%"5D"%"7D"%"5D"%"2C"%"22termExpressions"%"22"%"3A"%"5B"%"5D"%"2C"%"22similarityExpressions"%"22"%"3A"%"5B"%"5D"%"7D" --compressed
 
 	var workItems []*WorkItem
@@ -264,7 +258,7 @@ func (rtc *RTC) Query(filters []Filter, sortColumn string, sortAscending bool, m
 
 	queryUrl := "https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IQueryRestService/getResultSet"
 	// data := fmt.Sprintf("startIndex=0&maxResults=50&filterAttribute=&filterValue=&columnIdentifiers=workItemType&columnIdentifiers=id&columnIdentifiers=summary&columnIdentifiers=owner&columnIdentifiers=internalState&columnIdentifiers=internalPriority&columnIdentifiers=internalSeverity&columnIdentifiers=modified&sortColumns=modified&sortDirections=false&projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ&jsonExpression=%s", string(jsonStr))
-	data := fmt.Sprintf("startIndex=0&maxResults=%d&filterAttribute=&filterValue=&columnIdentifiers=workItemType&Q&columnIdentifiers=summary&columnIdentifiers=creator&columnIdentifiers=owner&columnIdentifiers=creationDate&columnIdentifiers=duration&columnIdentifiers=category&columnIdentifiers=target&columnIdentifiers=projectArea&columnIdentifiers=internalTags&columnIdentifiers=internalState&sortColumns=%s&sortDirections=%t&projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ&jsonExpression=%s", maxResults, sortColumn, sortAscending, string(jsonStr))
+	data := fmt.Sprintf("startIndex=%d&maxResults=%d&filterAttribute=&filterValue=&columnIdentifiers=workItemType&Q&columnIdentifiers=summary&columnIdentifiers=creator&columnIdentifiers=owner&columnIdentifiers=creationDate&columnIdentifiers=duration&columnIdentifiers=category&columnIdentifiers=target&columnIdentifiers=projectArea&columnIdentifiers=internalTags&columnIdentifiers=internalState&sortColumns=%s&sortDirections=%t&projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ&jsonExpression=%s", startIndex, maxResults, sortColumn, sortAscending, string(jsonStr))
 
 	// fmt.Println(string(jsonStr))
 	// fmt.Println(string(data))
@@ -281,7 +275,15 @@ func (rtc *RTC) Query(filters []Filter, sortColumn string, sortAscending bool, m
 		return workItems, err
 	}
 
-	for _, row := range env.Body.Response.ReturnValue.Value.Rows {
+	return workItemsFromRows(env.Body.Response.ReturnValue.Value.Rows), nil
+}
+
+// workItemsFromRows reads the rows of a result set with the columns Query
+// and RunSavedQuery ask for.
+func workItemsFromRows(rows []models.Row) []*WorkItem {
+	var workItems []*WorkItem
+
+	for _, row := range rows {
 		// fmt.Printf("Row: %+v\n", row)
 		// for i, l := range row.Labels {
 		// 	fmt.Printf("%d - %s\n", i, l)
@@ -307,12 +309,13 @@ func (rtc *RTC) Query(filters []Filter, sortColumn string, sortAscending bool, m
 			FiledAgainst: row.Labels[6],
 			PlannedFor:   row.Labels[7],
 			State:        row.Labels[10],
+			Tags:         splitTags(row.Labels[9]),
 			LocationUri:  row.LocationUri,
 		}
 		workItems = append(workItems, wi)
 	}
 
-	return workItems, nil
+	return workItems
 }
 
 func (rtc *RTC) Search(query string) ([]*WorkItem, error) {
@@ -347,23 +350,27 @@ func (rtc *RTC) Search(query string) ([]*WorkItem, error) {
 }
 
 func (rtc *RTC) GetInternalId(id string) (itemId string, stateId string, err error) {
-	url := fmt.Sprintf("https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IWorkItemRestService/workItemDTO2?includeHistory=false&id=%s&projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ", id)
-
-	body, err := rtc.requestBody("GET", url, "")
+	val, err := rtc.workItemDTO(id)
 	if err != nil {
 		return "", "", err
 	}
 
-	// fmt.Println("Body", string(body))
+	return val.ItemId, val.StateId, nil
+}
 
-	env, err := models.NewFromXml(body)
+func (rtc *RTC) workItemDTO(id string) (*models.Value, error) {
+	return rtc.fetchWorkItemDTO(id, false)
+}
+
+func (rtc *RTC) fetchWorkItemDTO(id string, history bool) (*models.Value, error) {
+	url := fmt.Sprintf("https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IWorkItemRestService/workItemDTO2?includeHistory=%t&id=%s&projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ", history, id)
+
+	env, err := rtc.requestXml("GET", url, "")
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
-	val := env.Body.Response.ReturnValue.Value
-
-	return val.ItemId, val.StateId, nil
+	return &env.Body.Response.ReturnValue.Value, nil
 }
 
 func (rtc *RTC) CreateNewId(wiType string) (string, error) {
@@ -405,39 +412,38 @@ func (rtc *RTC) Retrieve(id string) (*WorkItem, error) {
 	return wi, nil
 }
 
+// Create creates the work item with its summary, type, description, owner,
+// category, iteration, priority, severity, tags and estimate, all given as
+// labels. The owner defaults to the configured one, and the defaults for the
+// work item type are applied to the attributes that were not given.
 func (rtc *RTC) Create(wi *WorkItem) (*WorkItem, error) {
-	wiType := strings.ToLower(wi.Type)
-	itemId, err := rtc.CreateNewId(wiType)
-
+	wiType, err := rtc.ResolveType(wi.Type)
 	if err != nil {
 		return nil, err
 	}
 
-	var values map[string]string
-	values = make(map[string]string)
+	itemId, err := rtc.CreateNewId(wiType)
+	if err != nil {
+		return nil, err
+	}
 
-	values["summary"] = url.QueryEscape(wi.Summary)
-	values["workItemType"] = url.QueryEscape(wiType)
-	values["work_product_where_found"] = "Work_Product_where_found.literal.l2"
-	values["owner"] = rtc.OwnerId
+	values, err := rtc.createValues(wi, wiType)
+	if err != nil {
+		return nil, err
+	}
 
 	createUrl := "https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IWorkItemRestService/workItem2"
-	data := fmt.Sprintf("itemId=%s&type=task&additionalSaveParameters=com.ibm.team.workitem.common.internal.updateBacklinks&sanitizeHTML=true&projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ", itemId)
+	data := fmt.Sprintf("itemId=%s&type=%s&additionalSaveParameters=com.ibm.team.workitem.common.internal.updateBacklinks&sanitizeHTML=true&projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ", itemId, url.QueryEscape(wiType))
 
-	for k, _ := range values {
-		data = data + "&attributeIdentifiers=" + k // url.QueryEscape(k)
-		// fmt.Printf("%s => %s\n", k, url.QueryEscape(k))
+	keys := sortedKeys(values)
+	for _, k := range keys {
+		data = data + "&attributeIdentifiers=" + url.QueryEscape(k)
 	}
 
-	for _, v := range values {
-		data = data + "&attributeValues=" + v // url.QueryEscape(v)
-		// fmt.Printf("%s => %s\n", v, url.QueryEscape(v))
+	for _, k := range keys {
+		data = data + "&attributeValues=" + url.QueryEscape(values[k])
 	}
 
-	// fmt.Println("\n\nTrying URL:", createUrl, data)
-	// fmt.Println("\n")
-
-	// return nil, nil
 	env, err := rtc.requestXml("POST", createUrl, data)
 	if err != nil {
 		return nil, err
@@ -453,6 +459,86 @@ func (rtc *RTC) Create(wi *WorkItem) (*WorkItem, error) {
 	return rwi, nil
 }
 
+func (rtc *RTC) createValues(wi *WorkItem, wiType string) (map[string]string, error) {
+	c, err := rtc.GetValueCatalog(wiType)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{
+		"summary":      wi.Summary,
+		"workItemType": wiType,
+		"owner":        rtc.OwnerId,
+	}
+
+	if wi.Description != "" {
+		values["description"] = textToHTML(wi.Description)
+	}
+
+	if wi.Estimate != "" {
+		values["duration"], err = durationValue(wi.Estimate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(wi.Tags) > 0 {
+		values["internalTags"] = strings.Join(wi.Tags, ", ")
+	}
+
+	if wi.PlannedFor != "" {
+		iter, err := rtc.FindIteration(wi.PlannedFor)
+		if err != nil {
+			return nil, err
+		}
+		values["target"] = iter.ItemId
+	}
+
+	labels := map[string]string{
+		"owner":            wi.OwnedBy,
+		"category":         wi.FiledAgainst,
+		"internalPriority": wi.Priority,
+		"internalSeverity": wi.Severity,
+	}
+
+	for attr, label := range labels {
+		if label == "" {
+			continue
+		}
+
+		values[attr], err = c.Lookup(attr, label)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for attr, label := range rtc.defaultsFor(wi.Type, wiType) {
+		if _, ok := values[attr]; ok {
+			continue
+		}
+
+		values[attr], err = c.Lookup(attr, label)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// defaultsFor returns the default attribute values for the work item type,
+// given either by its label or its id, on top of the defaults for every type.
+func (rtc *RTC) defaultsFor(label string, wiType string) map[string]string {
+	m := map[string]string{}
+	for _, key := range []string{"*", strings.ToLower(label), strings.ToLower(wiType)} {
+		for k, v := range rtc.Defaults[key] {
+			m[k] = v
+		}
+	}
+
+	return m
+}
+
 func (rtc *RTC) Request(method string, url string) ([]byte, error) {
 	resp, err := rtc.requestBody(method, url, "")
 	if err != nil {
@@ -473,6 +559,8 @@ func (rtc *RTC) GetReleases() ([]models.Release, error) {
 	return env.Body.Response.ReturnValue.Releases, nil
 }
 
+// GetIterations returns the iterations of every release, each one followed
+// by its own iterations.
 func (rtc *RTC) GetIterations() ([]models.Iteration, error) {
 	rels, err := rtc.GetReleases()
 	if err != nil {
@@ -482,14 +570,23 @@ func (rtc *RTC) GetIterations() ([]models.Iteration, error) {
 	var m []models.Iteration
 
 	for _, rel := range rels {
-		for _, iter := range rel.Iterations {
-			m = append(m, iter)
-		}
+		m = appendIterations(m, rel.Iterations)
 	}
 
 	return m, nil
 }
 
+// appendIterations appends the iterations and, after each one, its own
+// iterations.
+func appendIterations(m []models.Iteration, iters []models.Iteration) []models.Iteration {
+	for _, iter := range iters {
+		m = append(m, iter)
+		m = appendIterations(m, iter.Iterations)
+	}
+
+	return m
+}
+
 func (rtc *RTC) GetIterationsMap() (map[string]models.Iteration, error) {
 	rels, err := rtc.GetReleases()
 	if err != nil {
@@ -500,10 +597,7 @@ func (rtc *RTC) GetIterationsMap() (map[string]models.Iteration, error) {
 	m = make(map[string]models.Iteration)
 
 	for _, rel := range rels {
-		// fmt.Println("Release:", rel.Label)
-		// fmt.Println("Iterations:", len(rel.Iterations))
-		for _, iter := range rel.Iterations {
-			// fmt.Println("   -", iter.ItemId, "-", iter.Label)
+		for _, iter := range appendIterations(nil, rel.Iterations) {
 			m[iter.ItemId] = iter
 		}
 	}
@@ -515,6 +609,17 @@ type Attributed interface {
 	GetAttributes() []*models.Attribute
 }
 
+func splitTags(s string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
 func getAttributes(a Attributed) map[string]string {
 	var m map[string]string
 	m = make(map[string]string)
@@ -529,7 +634,23 @@ func getAttributes(a Attributed) map[string]string {
 	return m
 }
 
-func makeRef(link models.Link) Reference {
+// millisAttribute returns the duration of attributes such as duration and
+// timeSpent, which carry the milliseconds as their ids and -1 when unset.
+func millisAttribute(val *models.Value, key string) time.Duration {
+	attr := val.Attribute(key)
+	if attr == nil {
+		return 0
+	}
+
+	ms, err := strconv.ParseInt(attr.Id, 10, 64)
+	if err != nil || ms < 0 {
+		return 0
+	}
+
+	return time.Duration(ms) * time.Millisecond
+}
+
+func makeRef(lt models.LinkType, link models.Link) Reference {
 	refAttrs := getAttributes(link.Target)
 	ref := Reference{
 		ItemId:      link.Target.ItemId,
@@ -538,6 +659,20 @@ func makeRef(link models.Link) Reference {
 		Summary:     refAttrs["summary"],
 		Type:        refAttrs["workItemType"],
 		Id:          refAttrs["id"],
+		LinkType:    lt.DisplayName,
+	}
+
+	// links to anything other than work items only carry a comment
+	if ref.Summary == "" {
+		ref.Summary = link.Comment
+	}
+
+	if ref.Type == "" {
+		ref.Type = lt.ItemType
+	}
+
+	if ref.LocationUri == "" {
+		ref.LocationUri = link.Url
 	}
 
 	return ref
@@ -565,189 +700,245 @@ func (rtc *RTC) GetWorkItem(id string) (*WorkItem, error) {
 	wi.Resolution = attrs["internalResolution"]
 	wi.TimeSpent = attrs["timeSpent"]
 	wi.Estimate = attrs["duration"]
+	wi.EstimateTime = millisAttribute(&val, "duration")
+	wi.SpentTime = millisAttribute(&val, "timeSpent")
 	wi.CodeChanges = attrs["code-change"]
-
-	// add parents
-	if len(val.LinkTypes) > 0 {
-		for _, lt := range val.LinkTypes {
-
-			if lt.EndpointId == "parent" {
-				for _, link := range lt.Links {
-					ref := makeRef(link)
-					wi.Parents = append(wi.Parents, ref)
-				}
-			}
-
-			if lt.EndpointId == "children" {
-				for _, link := range lt.Links {
-					ref := makeRef(link)
-					wi.Children = append(wi.Children, ref)
-				}
-			}
+	wi.FiledAgainst = attrs["category"]
+	wi.Priority = attrs["internalPriority"]
+	wi.Severity = attrs["internalSeverity"]
+	wi.Tags = splitTags(attrs["internalTags"])
+
+	// add links, parents and children
+	wi.Links = make(map[string][]Reference)
+	for _, lt := range val.LinkTypes {
+		for _, link := range lt.Links {
+			wi.Links[lt.EndpointId] = append(wi.Links[lt.EndpointId], makeRef(lt, link))
 		}
 	}
 
+	wi.Parents = wi.Links["parent"]
+	wi.Children = wi.Links["children"]
+
 	// add approvals
 	wi.Approvals = val.Approvals
 
+	wi.Comments = makeComments(&val)
+
 	return wi, nil
 }
 
+// ChangeStatus performs the workflow action, given by its id or label, on the
+// work item.
 func (rtc *RTC) ChangeStatus(id string, s string) (*models.Envelope, error) {
-	itemId, stateId, err := rtc.GetInternalId(id)
+	base, err := rtc.workItemDTO(id)
 	if err != nil {
 		return nil, err
 	}
 
-	changeUrl := "https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IWorkItemRestService/workItem2"
-	data := fmt.Sprintf("attributeIdentifiers=internalResolution&attributeValues=&action=bugzillaWorkflow.action.%s&itemId=%s&type=task&stateId=%s&additionalSaveParameters=com.ibm.team.workitem.common.internal.updateBacklinks&sanitizeHTML=true&projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ", s, itemId, stateId)
-	env, err := rtc.requestXml("POST", changeUrl, data)
+	action, err := rtc.resolveAction(base, s)
 	if err != nil {
 		return nil, err
 	}
 
-	return env, err
+	return rtc.save(id, base, nil, action, "")
 }
 
-func (rtc *RTC) PerformAction(name string, id string, action string, expectedState string) error {
-	env, err := rtc.ChangeStatus(id, action)
+// PerformAction performs the workflow action on the work item and fails if
+// its state didn't change.
+func (rtc *RTC) PerformAction(name string, id string, action string) error {
+	_, err := rtc.Transition(id, action, "")
 	if err != nil {
-		return err
+		return errors.New("Failed to " + name + " work item " + id + ": " + err.Error())
 	}
 
-	attrs := getAttributes(env.Body.Response.ReturnValue.Value.WorkItem)
+	return nil
+}
 
-	if state, ok := attrs["internalState"]; ok {
-		if state == expectedState {
-			return nil
-		}
-	}
+func (rtc *RTC) Close(id string) error {
+	return rtc.PerformAction("close", id, "close")
+}
 
-	wi, err := rtc.GetWorkItem(id)
+func (rtc *RTC) SetAttributes(id string, attrs map[string]string) (*models.Envelope, error) {
+	base, err := rtc.workItemDTO(id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return errors.New("Failed to " + name + " work item " + id + ". Current state is " + wi.State + ".")
+	return rtc.save(id, base, attrs, "", "")
 }
 
-func (rtc *RTC) Close(id string) error {
-	env, err := rtc.ChangeStatus(id, "close")
-	if err != nil {
-		return err
-	}
+func (rtc *RTC) SaveAttribute(id string, attr string, value string) (*models.Envelope, error) {
+	return rtc.SetAttributes(id, map[string]string{attr: value})
+}
+
+// save posts the attribute values and, if given, a workflow action id to a work
+// item in a single request, so they are either all applied or none is. The
+// save is based on the state of the work item in base and fails with an
+// ErrConflict if someone else changed the work item since, unless merging is
+// enabled and none of the changed attributes were changed by them.
+func (rtc *RTC) save(id string, base *models.Value, attrs map[string]string, action string, extra string) (*models.Envelope, error) {
+	env, _, err := rtc.saveFrom(id, base, attrs, action, extra)
+	return env, err
+}
+
+// saveFrom saves like save and also returns the state of the work item the
+// save was finally based on, which is newer than base after a merge.
+func (rtc *RTC) saveFrom(id string, base *models.Value, attrs map[string]string, action string, extra string) (*models.Envelope, *models.Value, error) {
+	changeUrl := "https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IWorkItemRestService/workItem2"
+
+	for attempt := 0; ; attempt++ {
+		data := fmt.Sprintf("type=%s&itemId=%s&stateId=%s&additionalSaveParameters=com.ibm.team.workitem.common.internal.updateBacklinks&sanitizeHTML=true&projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ", typeOf(base), base.ItemId, base.StateId)
+
+		if action != "" {
+			data += "&action=" + url.QueryEscape(action)
+		}
 
-	attrs := getAttributes(env.Body.Response.ReturnValue.Value.WorkItem)
+		for _, k := range sortedKeys(attrs) {
+			data += fmt.Sprintf("&attributeIdentifiers=%s&attributeValues=%s", k, url.QueryEscape(attrs[k]))
+		}
+
+		data += extra
 
-	if state, ok := attrs["internalState"]; ok {
-		if state == "Closed" {
-			return nil
+		env, err := rtc.postWorkItem(id, changeUrl, data)
+		conflict, ok := err.(*ErrConflict)
+		if !ok || !rtc.Merge || attempt >= maxMergeAttempts {
+			return env, base, err
+		}
+
+		conflict.Fields = conflictingFields(base, conflict.Attributes, attrs, action)
+		if len(conflict.Fields) > 0 {
+			return nil, base, conflict
+		}
+
+		base, err = rtc.workItemDTO(id)
+		if err != nil {
+			return nil, base, err
 		}
 	}
+}
 
-	wi, err := rtc.GetWorkItem(id)
-	if err != nil {
-		return err
+func sortedKeys(m map[string]string) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 
-	return errors.New("Failed to close work item " + id + ". Current state is " + wi.State + ".")
+	return keys
 }
 
-func (rtc *RTC) SetAttributes(id string, attrs map[string]string) (*models.Envelope, error) {
-	itemId, stateId, err := rtc.GetInternalId(id)
-	if err != nil {
-		return nil, err
-	}
+type Change struct {
+	Attribute string `json:"attribute"`
+	Field     string `json:"field"`
+	Old       string `json:"old"`
+	New       string `json:"new"`
+}
 
-	changeUrl := "https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IWorkItemRestService/workItem2"
-	data := fmt.Sprintf("type=task&itemId=%s&stateId=%s&additionalSaveParameters=com.ibm.team.workitem.common.internal.updateBacklinks&sanitizeHTML=true&projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ", itemId, stateId)
+var attributeNames = map[string]string{
+	"duration":           "Estimate",
+	"timeSpent":          "Time spent",
+	"target":             "Planned for",
+	"internalState":      "State",
+	"internalResolution": "Resolution",
+}
 
-	for k, v := range attrs {
-		data += fmt.Sprintf("&attributeIdentifiers=%s&attributeValues=%s", k, v)
+// Update saves the estimate, time spent, iteration and resolution set on the
+// work item, along with the workflow action, if any, in a single save. The
+// action and resolution can be given by id or label and must be valid from
+// the current state. It returns the fields that were changed.
+func (rtc *RTC) Update(wi WorkItem, action string) ([]Change, error) {
+	m := make(map[string]string)
+
+	if wi.Id == "" {
+		return nil, errors.New("Missing work item id")
 	}
 
-	env, err := rtc.requestXml("POST", changeUrl, data)
-	if err != nil {
-		return nil, err
+	if wi.TimeSpent != "" {
+		v, err := durationValue(wi.TimeSpent)
+		if err != nil {
+			return nil, err
+		}
+		m["timeSpent"] = v
 	}
 
-	return env, err
-}
+	if wi.Estimate != "" {
+		v, err := durationValue(wi.Estimate)
+		if err != nil {
+			return nil, err
+		}
+		m["duration"] = v
+	}
 
-func (rtc *RTC) SaveAttribute(id string, attr string, value string) (*models.Envelope, error) {
-	itemId, stateId, err := rtc.GetInternalId(id)
-	if err != nil {
-		return nil, err
+	if wi.IterationId != "" {
+		iter, err := rtc.FindIteration(wi.IterationId)
+		if err != nil {
+			return nil, err
+		}
+
+		m["target"] = iter.ItemId
 	}
 
-	changeUrl := "https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IWorkItemRestService/workItem2"
-	data := fmt.Sprintf("attributeIdentifiers=%s&attributeValues=%s&itemId=%s&type=task&stateId=%s&additionalSaveParameters=com.ibm.team.workitem.common.internal.updateBacklinks&sanitizeHTML=true&projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ", attr, value, itemId, stateId)
+	if len(m) < 1 && action == "" && wi.Resolution == "" {
+		return nil, errors.New("Nothing to update on work item " + wi.Id)
+	}
 
-	env, err := rtc.requestXml("POST", changeUrl, data)
+	before, err := rtc.workItemDTO(wi.Id)
 	if err != nil {
 		return nil, err
 	}
 
-	return env, err
-}
+	if action != "" {
+		action, err = rtc.resolveAction(before, action)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-func (rtc *RTC) AddParent(id string, parentId string) error {
-	wi, err := rtc.GetWorkItem(id)
-	if err != nil {
-		return err
+	if wi.Resolution != "" {
+		m["internalResolution"], err = rtc.resolveResolution(before, wi.Resolution)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	pwi, err := rtc.GetWorkItem(parentId)
+	// diff against what the save was based on, so changes merged in by others
+	// aren't reported as ours
+	_, before, err = rtc.saveFrom(wi.Id, before, m, action, "")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	link := url.QueryEscape(fmt.Sprintf(`{"cmd":"addLink","type":"com.ibm.team.workitem.linktype.parentworkitem","end":"target","name":"Parent","itemId":"%s","comment":"%s: %s"}`, pwi.ItemId, pwi.Id, pwi.Summary))
-
-	linkUrl := "https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IWorkItemRestService/workItem2"
-	data := fmt.Sprintf("itemId=%s&type=task&stateId=%s&updateLinks=%s&additionalSaveParameters=com.ibm.team.workitem.common.internal.updateBacklinks&sanitizeHTML=true&projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ", wi.ItemId, wi.StateId, link)
-
-	_, err = rtc.requestBody("POST", linkUrl, data)
+	after, err := rtc.workItemDTO(wi.Id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
-}
-
-func (rtc *RTC) Update(wi WorkItem) error {
-	m := make(map[string]string)
+	oldAttrs := getAttributes(before)
+	newAttrs := getAttributes(after)
 
-	if wi.TimeSpent != "" {
-		m["timeSpent"] = wi.TimeSpent
-	}
-
-	if wi.Estimate != "" {
-		m["duration"] = wi.Estimate
+	keys := sortedKeys(m)
+	if action != "" {
+		if oldAttrs["internalState"] == newAttrs["internalState"] {
+			return nil, errors.New("Work item " + wi.Id + " is still " + newAttrs["internalState"] + ".")
+		}
+		keys = append(keys, "internalState")
 	}
 
-	if wi.IterationId != "" {
-		iters, err := rtc.GetIterations()
-		if err != nil {
-			return err
+	changes := []Change{}
+	for _, k := range keys {
+		if oldAttrs[k] == newAttrs[k] {
+			continue
 		}
 
-		iterIdNum, err := strconv.Atoi(wi.IterationId)
-
-		if iterIdNum > len(iters) {
-			return errors.New("Iteration with id " + wi.IterationId + " not found. Use iterations command.")
+		name, ok := attributeNames[k]
+		if !ok {
+			name = k
 		}
 
-		iter := iters[iterIdNum]
-		m["target"] = iter.ItemId
-	}
-
-	if wi.Id == "" {
-		return errors.New("Missing work item id")
+		changes = append(changes, Change{Attribute: k, Field: name, Old: oldAttrs[k], New: newAttrs[k]})
 	}
 
-	_, err := rtc.SetAttributes(wi.Id, m)
-	return err
+	return changes, nil
 }
 
 func (rtc *RTC) MoveToIteration(id string, iterId string) (*WorkItem, models.Iteration, error) {
@@ -762,20 +953,11 @@ func (rtc *RTC) MoveToIteration(id string, iterId string) (*WorkItem, models.Ite
 		return nil, iter, errors.New("Work item with id " + id + " not found. Use list command.")
 	}
 
-	iters, err := rtc.GetIterations()
+	iter, err = rtc.FindIteration(iterId)
 	if err != nil {
 		return nil, iter, err
 	}
 
-	iterIdNum, err := strconv.Atoi(iterId)
-
-	if iterIdNum > len(iters) {
-		return nil, iter, errors.New("Iteration with id " + iterId + " not found. Use iterations command.")
-	}
-
-	iter = iters[iterIdNum]
-	// fmt.Println("Iter:", iter.ItemId, "-", iter.Label)
-
 	_, err = rtc.SaveAttribute(id, "target", iter.ItemId)
 	if err != nil {
 		return nil, iter, err
@@ -784,17 +966,18 @@ func (rtc *RTC) MoveToIteration(id string, iterId string) (*WorkItem, models.Ite
 	return wi, iter, nil
 }
 
-func (rtc *RTC) CreateSubTask(id string, subType string) (*WorkItem, error) {
+// CreateSubTask creates a task from the template under the work item.
+func (rtc *RTC) CreateSubTask(id string, t SubtaskTemplate) (*WorkItem, error) {
 	pwi, err := rtc.Retrieve(id)
 	if err != nil {
 		return nil, err
 	}
 
-	summary := fmt.Sprintf("%s: %s", subType, pwi.Summary)
-
 	wi := &WorkItem{
-		Summary: summary,
-		Type:    "task",
+		Summary:  t.summaryFor(pwi),
+		Type:     "task",
+		Estimate: t.Estimate,
+		OwnedBy:  t.Owner,
 	}
 
 	wi, err = rtc.Create(wi)
@@ -811,7 +994,11 @@ func (rtc *RTC) CreateSubTask(id string, subType string) (*WorkItem, error) {
 }
 
 func (rtc *RTC) GetAllValues() (map[string]map[string]string, error) {
-	allValuesUrl := "https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IWorkItemRestService/allValues?projectAreaItemId=_U7zMYFRcEd61fuNW84kdiQ&typeId=task&includeArchived=false&ids=workItemType&ids=internalSeverity&ids=foundIn&ids=creator&ids=category&ids=internalTags&ids=internalPriority&ids=owner&ids=target&ids=task&ids=key-component&ids=environment&itemId=_IDpPV6fhEeSicYpAbHXWsw"
+	return rtc.GetAllValuesForType("task")
+}
+
+func (rtc *RTC) GetAllValuesForType(wiType string) (map[string]map[string]string, error) {
+	allValuesUrl := fmt.Sprintf("https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IWorkItemRestService/allValues?projectAreaItemId=%s&typeId=%s&includeArchived=false&ids=workItemType&ids=internalSeverity&ids=foundIn&ids=creator&ids=category&ids=internalTags&ids=internalPriority&ids=owner&ids=target&ids=task&ids=key-component&ids=environment&itemId=_IDpPV6fhEeSicYpAbHXWsw", projectAreaItemId, url.QueryEscape(wiType))
 
 	env, err := rtc.requestXml("GET", allValuesUrl, "")
 	if err != nil {
@@ -822,9 +1009,13 @@ func (rtc *RTC) GetAllValues() (map[string]map[string]string, error) {
 }
 
 func (rtc *RTC) GetOwners() ([]Owner, error) {
-	values, err := rtc.GetAllValues()
+	c, err := rtc.GetValueCatalog("task")
 	owners := []Owner{}
-	for k, v := range values["owner"] {
+	if err != nil {
+		return owners, err
+	}
+
+	for k, v := range c.values["owner"] {
 		owners = append(owners, Owner{Id: k, Name: v})
 	}
 
@@ -841,24 +1032,6 @@ func (rtc *RTC) OpenWorkItem(id string) error {
 	return open.Start(wi.LocationUri)
 }
 
-func (rtc *RTC) AddApproval(id string, desc string, ownerId string) error {
-	itemId, stateId, err := rtc.GetInternalId(id)
-	if err != nil {
-		return err
-	}
-
-	createUrl := "https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IWorkItemRestService/workItem2"
-	approval := fmt.Sprintf(`{"cmd":"createApproval","param":{"type":"com.ibm.team.workitem.approvalType.approval","name":"%s","approvers":[{"user":"%s","state":"com.ibm.team.workitem.approvalState.pending"}]}}`, desc, ownerId)
-	data := fmt.Sprintf("attributeIdentifiers=internalResolution&attributeValues=&itemId=%s&type=task&stateId=%s&updateApprovals=%s", itemId, stateId, approval)
-
-	_, err = rtc.requestXml("POST", createUrl, data)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
 // values["category"] = "_aXl2IGW0Ed6uZsIllQzRvg"
 // values["owner"] = "_PrOIoMZ5Ed-Lr-wDR3V_pA"
 // values["target"] = "_H5fMQaHXEeS4fen3HD7Mow"
@@ -0,0 +1,67 @@
+package rtc
+
+import (
+	"strings"
+)
+
+// SubtaskTemplate describes a subtask to be created under a work item. The
+// summary defaults to "<Type>: <parent summary>", and "{parent}" in it is
+// replaced by the parent summary.
+type SubtaskTemplate struct {
+	Type     string `json:"type"`
+	Summary  string `json:"summary,omitempty"`
+	Estimate string `json:"estimate,omitempty"`
+	Owner    string `json:"owner,omitempty"`
+}
+
+type SubtaskResult struct {
+	Template SubtaskTemplate
+	WorkItem *WorkItem
+	Existing *Reference
+	Err      error
+}
+
+func (t SubtaskTemplate) summaryFor(parent *WorkItem) string {
+	if t.Summary == "" {
+		return t.Type + ": " + parent.Summary
+	}
+
+	return strings.Replace(t.Summary, "{parent}", parent.Summary, -1)
+}
+
+// existing returns the child of the work item that the template would create,
+// matched by summary or by starting with the template type.
+func (t SubtaskTemplate) existing(parent *WorkItem) *Reference {
+	summary := strings.ToLower(t.summaryFor(parent))
+	prefix := strings.ToLower(t.Type + ":")
+
+	for i, c := range parent.Children {
+		s := strings.ToLower(strings.TrimSpace(c.Summary))
+		if s == summary || strings.HasPrefix(s, prefix) {
+			return &parent.Children[i]
+		}
+	}
+
+	return nil
+}
+
+// CreateSubTasks creates the subtasks of the templates under the work item,
+// skipping the ones it already has as children, and carries on when one of
+// them fails.
+func (rtc *RTC) CreateSubTasks(id string, templates []SubtaskTemplate) ([]SubtaskResult, error) {
+	parent, err := rtc.GetWorkItem(id)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []SubtaskResult{}
+	for _, t := range templates {
+		res := SubtaskResult{Template: t, Existing: t.existing(parent)}
+		if res.Existing == nil {
+			res.WorkItem, res.Err = rtc.CreateSubTask(id, t)
+		}
+		results = append(results, res)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,41 @@
+package rtc
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+)
+
+// LogWork adds the duration to the time spent on the work item and comments
+// on it with the log entry, in a single save. It returns the new time spent.
+func (rtc *RTC) LogWork(id string, d time.Duration, message string) (time.Duration, error) {
+	if d <= 0 {
+		return 0, errors.New("Can't log a duration of zero or less")
+	}
+
+	base, err := rtc.workItemDTO(id)
+	if err != nil {
+		return 0, err
+	}
+
+	total := millisAttribute(base, "timeSpent") + d
+
+	text := "Logged " + FormatDuration(d)
+	if message != "" {
+		text += ": " + message
+	}
+
+	cmd, err := json.Marshal(map[string]string{"cmd": "addComment", "content": textToHTML(text)})
+	if err != nil {
+		return 0, err
+	}
+
+	attrs := map[string]string{"timeSpent": millis(total)}
+	_, err = rtc.save(id, base, attrs, "", "&updateComments="+url.QueryEscape(string(cmd)))
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
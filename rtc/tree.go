@@ -0,0 +1,164 @@
+package rtc
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const treeWorkers = 8
+
+type TreeNode struct {
+	WorkItem      *WorkItem
+	Children      []*TreeNode
+	TotalEstimate time.Duration
+
+	// Shared nodes were already expanded somewhere else in the tree, cycle
+	// nodes are their own ancestors and truncated nodes have children beyond
+	// the depth limit. None of them have their children expanded.
+	Shared    bool
+	Cycle     bool
+	Truncated bool
+}
+
+// GetTree returns the hierarchy of a work item: the chain of its parents up to
+// the root, as the returned node, and its descendants up to depth levels
+// below it. Work items are fetched concurrently, level by level.
+func (rtc *RTC) GetTree(id string, depth int) (*TreeNode, error) {
+	items := make(map[string]*WorkItem)
+
+	err := rtc.fetchWorkItems([]string{id}, items)
+	if err != nil {
+		return nil, err
+	}
+
+	// walk the descendants level by level
+	level := []string{id}
+	for d := 0; d < depth && len(level) > 0; d++ {
+		next := []string{}
+		for _, i := range level {
+			for _, c := range items[i].Children {
+				if _, ok := items[c.Id]; !ok {
+					next = append(next, c.Id)
+				}
+			}
+		}
+
+		err = rtc.fetchWorkItems(next, items)
+		if err != nil {
+			return nil, err
+		}
+		level = next
+	}
+
+	node := buildTree(id, items, depth, map[string]bool{}, map[string]bool{})
+
+	// then climb up the parents to the root, rolling up only the estimates of
+	// the branch that leads to the work item
+	seen := map[string]bool{id: true}
+	for len(node.WorkItem.Parents) > 0 {
+		parentId := node.WorkItem.Parents[0].Id
+		if seen[parentId] {
+			return nil, errors.New("Work item " + parentId + " is its own ancestor")
+		}
+		seen[parentId] = true
+
+		err = rtc.fetchWorkItems([]string{parentId}, items)
+		if err != nil {
+			return nil, err
+		}
+
+		node = &TreeNode{
+			WorkItem:      items[parentId],
+			Children:      []*TreeNode{node},
+			TotalEstimate: items[parentId].EstimateTime + node.TotalEstimate,
+		}
+	}
+
+	return node, nil
+}
+
+func buildTree(id string, items map[string]*WorkItem, depth int, path map[string]bool, expanded map[string]bool) *TreeNode {
+	wi := items[id]
+	node := &TreeNode{WorkItem: wi, TotalEstimate: wi.EstimateTime}
+
+	if path[id] {
+		node.Cycle = true
+		return node
+	}
+
+	if expanded[id] {
+		node.Shared = true
+		return node
+	}
+
+	if depth < 1 {
+		node.Truncated = len(wi.Children) > 0
+		return node
+	}
+
+	expanded[id] = true
+	path[id] = true
+	defer delete(path, id)
+
+	for _, c := range wi.Children {
+		if _, ok := items[c.Id]; !ok {
+			continue
+		}
+
+		child := buildTree(c.Id, items, depth-1, path, expanded)
+		if !child.Shared && !child.Cycle {
+			node.TotalEstimate += child.TotalEstimate
+		}
+		node.Children = append(node.Children, child)
+	}
+
+	return node
+}
+
+// fetchWorkItems gets the work items not yet in items concurrently.
+func (rtc *RTC) fetchWorkItems(ids []string, items map[string]*WorkItem) error {
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+
+	pending := []string{}
+	queued := map[string]bool{}
+	for _, id := range ids {
+		if _, ok := items[id]; ok || queued[id] {
+			continue
+		}
+		queued[id] = true
+		pending = append(pending, id)
+	}
+
+	queue := make(chan string)
+
+	for w := 0; w < treeWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range queue {
+				wi, err := rtc.GetWorkItem(id)
+
+				mutex.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				if err == nil {
+					items[id] = wi
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for _, id := range pending {
+		queue <- id
+	}
+	close(queue)
+
+	wg.Wait()
+
+	return firstErr
+}
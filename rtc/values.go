@@ -0,0 +1,160 @@
+package rtc
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const projectAreaItemId = "_U7zMYFRcEd61fuNW84kdiQ"
+
+// ValueCatalog maps the attribute values RTC knows about (owners, priorities,
+// iterations, categories...) between their internal ids and their labels.
+type ValueCatalog struct {
+	values map[string]map[string]string
+}
+
+func NewValueCatalog(values map[string]map[string]string) *ValueCatalog {
+	return &ValueCatalog{values: values}
+}
+
+// Has returns true if the catalog carries values for the given attribute.
+func (c *ValueCatalog) Has(attr string) bool {
+	_, ok := c.values[attr]
+	return ok
+}
+
+// Label returns the label for the value id of the given attribute, or the id
+// itself when it is unknown.
+func (c *ValueCatalog) Label(attr string, id string) string {
+	if label, ok := c.values[attr][id]; ok {
+		return strings.TrimSpace(label)
+	}
+
+	return id
+}
+
+// Lookup resolves a label into the id of a value of the given attribute. Ids
+// are accepted as they are, labels are matched exactly, then ignoring case and
+// finally by containing every word of the label. Attributes the catalog has
+// no values for are passed through unchanged, so raw ids keep working.
+func (c *ValueCatalog) Lookup(attr string, label string) (string, error) {
+	values, ok := c.values[attr]
+	if !ok {
+		return label, nil
+	}
+
+	if _, ok := values[label]; ok {
+		return label, nil
+	}
+
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return "", errors.New("Missing value for " + attr)
+	}
+
+	matches := c.match(values, func(l string) bool {
+		return l == label
+	})
+
+	if len(matches) < 1 {
+		matches = c.match(values, func(l string) bool {
+			return strings.EqualFold(l, label)
+		})
+	}
+
+	if len(matches) < 1 {
+		words := strings.Fields(strings.ToLower(label))
+		matches = c.match(values, func(l string) bool {
+			l = strings.ToLower(l)
+			for _, w := range words {
+				if !strings.Contains(l, w) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+
+	if len(matches) < 1 {
+		return "", errors.New(fmt.Sprintf("No %s matches \"%s\"", attr, label))
+	}
+
+	if len(matches) > 1 {
+		labels := []string{}
+		for _, id := range matches {
+			labels = append(labels, c.Label(attr, id))
+		}
+		sort.Strings(labels)
+		return "", errors.New(fmt.Sprintf("More than one %s matches \"%s\": %s", attr, label, strings.Join(labels, ", ")))
+	}
+
+	return matches[0], nil
+}
+
+func (c *ValueCatalog) match(values map[string]string, fn func(string) bool) []string {
+	ids := []string{}
+	for id, l := range values {
+		if fn(strings.TrimSpace(l)) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// GetValueCatalog returns the value catalog for the given work item type,
+// fetching it only once per project area and type.
+func (rtc *RTC) GetValueCatalog(wiType string) (*ValueCatalog, error) {
+	if wiType == "" {
+		wiType = "task"
+	}
+
+	key := projectAreaItemId + "/" + wiType
+
+	rtc.mutex.Lock()
+	defer rtc.mutex.Unlock()
+
+	if c, ok := rtc.catalogs[key]; ok {
+		return c, nil
+	}
+
+	values, err := rtc.GetAllValuesForType(wiType)
+	if err != nil {
+		return nil, err
+	}
+
+	if rtc.catalogs == nil {
+		rtc.catalogs = make(map[string]*ValueCatalog)
+	}
+
+	c := NewValueCatalog(values)
+	rtc.catalogs[key] = c
+
+	return c, nil
+}
+
+// Lookup resolves the label of a value of a task attribute into its id.
+func (rtc *RTC) Lookup(attr string, label string) (string, error) {
+	c, err := rtc.GetValueCatalog("task")
+	if err != nil {
+		return "", err
+	}
+
+	return c.Lookup(attr, label)
+}
+
+// ResolveType returns the type id for a work item type label such as "Story".
+func (rtc *RTC) ResolveType(wiType string) (string, error) {
+	c, err := rtc.GetValueCatalog("task")
+	if err != nil {
+		return "", err
+	}
+
+	if !c.Has("workItemType") {
+		return strings.ToLower(wiType), nil
+	}
+
+	return c.Lookup("workItemType", wiType)
+}
@@ -0,0 +1,112 @@
+package rtc
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/fcoury/rtc-go/models"
+)
+
+type Action struct {
+	Id    string
+	Label string
+}
+
+func typeOf(val *models.Value) string {
+	if attr := val.Attribute("workItemType"); attr != nil && attr.Id != "" {
+		return attr.Id
+	}
+
+	return "task"
+}
+
+// workflowValues fetches the workflow actions valid from the current state of
+// the work item and the resolutions its workflow offers.
+func (rtc *RTC) workflowValues(val *models.Value) (*ValueCatalog, error) {
+	valuesUrl := fmt.Sprintf("https://igartc01.swg.usma.ibm.com/jazz/service/com.ibm.team.workitem.common.internal.rest.IWorkItemRestService/allValues?projectAreaItemId=%s&typeId=%s&includeArchived=false&ids=workflowAction&ids=internalResolution&itemId=%s", projectAreaItemId, url.QueryEscape(typeOf(val)), val.ItemId)
+
+	env, err := rtc.requestXml("GET", valuesUrl, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewValueCatalog(env.Body.Response.ReturnValue.GetItems()), nil
+}
+
+// GetAvailableActions returns the workflow actions that can be performed on
+// the work item from its current state.
+func (rtc *RTC) GetAvailableActions(id string) ([]Action, error) {
+	val, err := rtc.workItemDTO(id)
+	if err != nil {
+		return nil, err
+	}
+
+	wf, err := rtc.workflowValues(val)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []Action{}
+	for _, actionId := range sortedKeys(wf.values["workflowAction"]) {
+		actions = append(actions, Action{Id: actionId, Label: wf.Label("workflowAction", actionId)})
+	}
+
+	return actions, nil
+}
+
+// GetResolutions returns the resolutions offered by the work item workflow.
+func (rtc *RTC) GetResolutions(id string) ([]Action, error) {
+	val, err := rtc.workItemDTO(id)
+	if err != nil {
+		return nil, err
+	}
+
+	wf, err := rtc.workflowValues(val)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []Action{}
+	for _, resId := range sortedKeys(wf.values["internalResolution"]) {
+		res = append(res, Action{Id: resId, Label: wf.Label("internalResolution", resId)})
+	}
+
+	return res, nil
+}
+
+// Transition performs the workflow action, given by id or label, on the work
+// item, optionally setting its resolution, and returns what changed.
+func (rtc *RTC) Transition(id string, action string, resolution string) ([]Change, error) {
+	return rtc.Update(WorkItem{Id: id, Resolution: resolution}, action)
+}
+
+func (rtc *RTC) resolveAction(val *models.Value, action string) (string, error) {
+	return rtc.resolveWorkflowValue(val, "workflowAction", "action", action)
+}
+
+func (rtc *RTC) resolveResolution(val *models.Value, resolution string) (string, error) {
+	return rtc.resolveWorkflowValue(val, "internalResolution", "resolution", resolution)
+}
+
+func (rtc *RTC) resolveWorkflowValue(val *models.Value, attr string, kind string, label string) (string, error) {
+	wf, err := rtc.workflowValues(val)
+	if err != nil {
+		return "", err
+	}
+
+	if len(wf.values[attr]) < 1 {
+		return "", fmt.Errorf("No %s is available for work item %s in state %s", kind, getAttributes(val)["id"], getAttributes(val)["internalState"])
+	}
+
+	id, err := wf.Lookup(attr, label)
+	if err != nil {
+		labels := []string{}
+		for _, i := range sortedKeys(wf.values[attr]) {
+			labels = append(labels, wf.Label(attr, i))
+		}
+		return "", fmt.Errorf("Invalid %s %s. Available from state %s: %s", kind, label, getAttributes(val)["internalState"], strings.Join(labels, ", "))
+	}
+
+	return id, nil
+}
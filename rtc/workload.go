@@ -0,0 +1,167 @@
+package rtc
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fcoury/rtc-go/models"
+)
+
+// AnyOwner is the capacity key that applies to everyone without a capacity
+// of their own.
+const AnyOwner = "*"
+
+type OwnerLoad struct {
+	Owner    Owner
+	Items    int
+	Estimate time.Duration
+	Capacity time.Duration
+
+	// Unparsed are the work items whose estimate couldn't be read, so it
+	// isn't part of Estimate.
+	Unparsed []*WorkItem
+}
+
+// Load is the estimate as a fraction of the capacity, zero when there is no
+// capacity to compare with.
+func (o *OwnerLoad) Load() float64 {
+	if o.Capacity <= 0 {
+		return 0
+	}
+
+	return float64(o.Estimate) / float64(o.Capacity)
+}
+
+func (o *OwnerLoad) Overallocated() bool {
+	return o.Capacity > 0 && o.Estimate > o.Capacity
+}
+
+type Workload struct {
+	Iteration models.Iteration
+	Owners    []*OwnerLoad
+
+	// UnknownOwners are the capacity keys that match no owner, which were
+	// skipped.
+	UnknownOwners []string
+}
+
+var openFilter = stateFilter("open or in progress")
+
+// GetWorkload sums the estimates of the open work items planned for the
+// iteration by owner, along with the capacity of each owner. Capacities are
+// keyed by owner name or id; owners with a capacity are listed even when they
+// have no work.
+func (rtc *RTC) GetWorkload(iterId string, capacity map[string]time.Duration) (*Workload, error) {
+	iter, err := rtc.FindIteration(iterId)
+	if err != nil {
+		return nil, err
+	}
+
+	owners, err := rtc.GetOwners()
+	if err != nil {
+		return nil, err
+	}
+
+	byName := map[string]Owner{}
+	for _, o := range owners {
+		byName[strings.ToLower(o.Name)] = o
+	}
+
+	workload := &Workload{Iteration: iter}
+
+	capacities := map[string]time.Duration{}
+	for key, d := range capacity {
+		if key == AnyOwner {
+			continue
+		}
+
+		o, ok := findOwner(owners, key)
+		if !ok {
+			workload.UnknownOwners = append(workload.UnknownOwners, key)
+			continue
+		}
+		capacities[o.Id] = d
+	}
+	sort.Strings(workload.UnknownOwners)
+
+	wis, err := rtc.QueryAll([]Filter{iterationFilter(iter.ItemId), openFilter}, "id", true)
+	if err != nil {
+		return nil, err
+	}
+
+	loads := map[string]*OwnerLoad{}
+	loadOf := func(o Owner) *OwnerLoad {
+		key := o.Id
+		if key == "" {
+			key = o.Name
+		}
+
+		l, ok := loads[key]
+		if !ok {
+			l = &OwnerLoad{Owner: o}
+			if d, ok := capacities[o.Id]; ok {
+				l.Capacity = d
+			} else if o.Id != "" && !strings.EqualFold(o.Name, "Unassigned") {
+				l.Capacity = capacity[AnyOwner]
+			}
+			loads[key] = l
+			workload.Owners = append(workload.Owners, l)
+		}
+		return l
+	}
+
+	for _, wi := range wis {
+		o, ok := byName[strings.ToLower(wi.OwnedBy)]
+		if !ok {
+			o = Owner{Name: wi.OwnedBy}
+		}
+
+		l := loadOf(o)
+		l.Items++
+		if wi.Estimate == "" {
+			continue
+		}
+
+		d, err := ParseDuration(wi.Estimate)
+		if err != nil {
+			l.Unparsed = append(l.Unparsed, wi)
+			continue
+		}
+		l.Estimate += d
+	}
+
+	for _, o := range owners {
+		if _, ok := capacities[o.Id]; ok {
+			loadOf(o)
+		}
+	}
+
+	sort.Stable(byLoad(workload.Owners))
+	return workload, nil
+}
+
+func findOwner(owners []Owner, key string) (Owner, bool) {
+	for _, o := range owners {
+		if o.Id == key || strings.EqualFold(o.Name, key) {
+			return o, true
+		}
+	}
+
+	return Owner{}, false
+}
+
+type byLoad []*OwnerLoad
+
+func (o byLoad) Len() int {
+	return len(o)
+}
+func (o byLoad) Swap(i, j int) {
+	o[i], o[j] = o[j], o[i]
+}
+func (o byLoad) Less(i, j int) bool {
+	if o[i].Load() != o[j].Load() {
+		return o[i].Load() > o[j].Load()
+	}
+	return o[i].Estimate > o[j].Estimate
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/fcoury/rtc-go/rtc"
+)
+
+// Timer is the work timer running, kept in the config dir so that it
+// survives the shell.
+type Timer struct {
+	Id      string    `json:"id"`
+	Summary string    `json:"summary"`
+	Type    string    `json:"type"`
+	Started time.Time `json:"started"`
+}
+
+func (t *Timer) Elapsed() time.Duration {
+	return time.Since(t.Started)
+}
+
+func readTimer() (*Timer, error) {
+	file, err := timerFile()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var t Timer
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("Invalid timer in %s: %s", file, err.Error())
+	}
+
+	return &t, nil
+}
+
+func writeTimer(t *Timer) error {
+	file, err := timerFile()
+	if err != nil {
+		return err
+	}
+
+	if t == nil {
+		err = os.Remove(file)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, data, 0600)
+}
+
+// stopTimer logs the time elapsed on the running timer, if any, and clears
+// it. Less than a minute is not logged.
+func stopTimer(r *rtc.RTC, t *Timer, message string) error {
+	wi := &rtc.WorkItem{Id: t.Id, Summary: t.Summary, Type: t.Type}
+
+	if d := t.Elapsed(); d < time.Minute {
+		fmt.Printf("Stopped timer on %s after less than a minute, nothing logged.\n", wi.Title())
+	} else if err := recordWork(r, wi, wholeMinutes(d), message); err != nil {
+		return err
+	}
+
+	return writeTimer(nil)
+}
+
+func timerStart(id string, startWorking bool) {
+	t, err := readTimer()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if t != nil && t.Id == id {
+		fmt.Printf("Timer already running on %s %s for %s.\n", t.Type, t.Id, rtc.FormatDuration(t.Elapsed()))
+		return
+	}
+
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	wi, err := r.Retrieve(id)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if t != nil {
+		if err := stopTimer(r, t, ""); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if startWorking {
+		fmt.Printf("Starting work on %s...\n", wi.Title())
+		if err := r.PerformAction("start", id, "start working"); err != nil {
+			fmt.Println(err.Error())
+		}
+	}
+
+	err = writeTimer(&Timer{Id: wi.Id, Summary: wi.Summary, Type: wi.Type, Started: time.Now()})
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Println("Timer started on", wi.Title())
+}
+
+func timerStop(message string) {
+	t, err := readTimer()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if t == nil {
+		fmt.Println("No timer running.")
+		return
+	}
+
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if err := stopTimer(r, t, message); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
+
+func timerStatus() {
+	t, err := readTimer()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if t == nil {
+		fmt.Println("No timer running.")
+		return
+	}
+
+	fmt.Printf("%s %s - %s\n", t.Type, t.Id, t.Summary)
+	fmt.Printf("Running since %s, for %s.\n", t.Started.Format("2006-01-02 15:04"), rtc.FormatDuration(t.Elapsed()))
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/gistia/tablewriter"
+)
+
+func transition(c *cli.Context, resolution string) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	ids, args, err := targetIds(r, c, false)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	action := strings.Join(args, " ")
+
+	if action == "" && len(ids) != 1 {
+		fmt.Println("Missing the action to perform on the work items")
+		os.Exit(1)
+	}
+
+	if len(ids) != 1 || c.String("from-query") != "" {
+		runBulk(r, "Transitioning", ids, func(id string) (string, error) {
+			changes, err := r.Transition(id, action, resolution)
+			return describeChanges(changes), err
+		})
+		return
+	}
+
+	id := ids[0]
+
+	if action == "" {
+		actions, err := r.GetAvailableActions(id)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if len(actions) < 1 {
+			fmt.Println("No workflow actions available for work item", id)
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Action", "Id"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		for _, a := range actions {
+			table.Append([]string{a.Label, a.Id})
+		}
+		table.Render()
+		return
+	}
+
+	fmt.Printf("Performing %s on work item %s...\n", action, id)
+	changes, err := r.Transition(id, action, resolution)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	showChanges(changes)
+
+	fmt.Println("\nWork item successfully updated.")
+}
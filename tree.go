@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fcoury/rtc-go/rtc"
+)
+
+func tree(id string, depth int, format string) {
+	if format != "ascii" && format != "json" && format != "dot" {
+		fmt.Println("Unknown format " + format + ". Use ascii, json or dot.")
+		return
+	}
+
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	root, err := r.GetTree(id, depth)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(jsonTree(root), "", "  ")
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		fmt.Println(string(data))
+	case "dot":
+		fmt.Println("digraph tree {")
+		fmt.Println("  node [shape=box];")
+		dotTree(root, map[string]bool{})
+		fmt.Println("}")
+	default:
+		asciiTree(root, id, "", "")
+	}
+}
+
+func formatHours(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", d.Hours()), "0"), ".") + "h"
+}
+
+func nodeLabel(n *rtc.TreeNode) string {
+	wi := n.WorkItem
+	label := fmt.Sprintf("%s %s - %s [%s, %s, %s", wi.Type, wi.Id, wi.Summary, wi.State, wi.Owner(), formatHours(wi.EstimateTime))
+	if len(n.Children) > 0 {
+		label += ", total " + formatHours(n.TotalEstimate)
+	}
+	label += "]"
+
+	switch {
+	case n.Cycle:
+		label += " (cycle)"
+	case n.Shared:
+		label += " (shown above)"
+	case n.Truncated:
+		label += " ..."
+	}
+
+	return label
+}
+
+func asciiTree(n *rtc.TreeNode, current string, prefix string, childPrefix string) {
+	marker := ""
+	if n.WorkItem.Id == current {
+		marker = " <"
+	}
+	fmt.Println(prefix + nodeLabel(n) + marker)
+
+	for i, c := range n.Children {
+		if i == len(n.Children)-1 {
+			asciiTree(c, current, childPrefix+"`-- ", childPrefix+"    ")
+		} else {
+			asciiTree(c, current, childPrefix+"|-- ", childPrefix+"|   ")
+		}
+	}
+}
+
+func dotTree(n *rtc.TreeNode, seen map[string]bool) {
+	wi := n.WorkItem
+	if !seen[wi.Id] {
+		seen[wi.Id] = true
+		label := fmt.Sprintf("%s %s\\n%s\\n%s, %s, %s", wi.Type, wi.Id, wi.Summary, wi.State, wi.Owner(), formatHours(n.TotalEstimate))
+		fmt.Printf("  \"%s\" [label=\"%s\"];\n", wi.Id, strings.Replace(label, `"`, `\"`, -1))
+	}
+
+	for _, c := range n.Children {
+		fmt.Printf("  \"%s\" -> \"%s\";\n", wi.Id, c.WorkItem.Id)
+		if !c.Shared && !c.Cycle {
+			dotTree(c, seen)
+		}
+	}
+}
+
+type treeJson struct {
+	Id            string      `json:"id"`
+	Type          string      `json:"type"`
+	Summary       string      `json:"summary"`
+	State         string      `json:"state"`
+	Owner         string      `json:"owner"`
+	Estimate      float64     `json:"estimateHours"`
+	TotalEstimate float64     `json:"totalEstimateHours"`
+	Shared        bool        `json:"shared,omitempty"`
+	Cycle         bool        `json:"cycle,omitempty"`
+	Truncated     bool        `json:"truncated,omitempty"`
+	Children      []*treeJson `json:"children,omitempty"`
+}
+
+func jsonTree(n *rtc.TreeNode) *treeJson {
+	wi := n.WorkItem
+	t := &treeJson{
+		Id:            wi.Id,
+		Type:          wi.Type,
+		Summary:       wi.Summary,
+		State:         wi.State,
+		Owner:         wi.OwnedBy,
+		Estimate:      wi.EstimateTime.Hours(),
+		TotalEstimate: n.TotalEstimate.Hours(),
+		Shared:        n.Shared,
+		Cycle:         n.Cycle,
+		Truncated:     n.Truncated,
+	}
+
+	for _, c := range n.Children {
+		t.Children = append(t.Children, jsonTree(c))
+	}
+
+	return t
+}
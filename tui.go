@@ -0,0 +1,743 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fcoury/rtc-go/rtc"
+	"github.com/kennygrant/sanitize"
+	"github.com/mattn/go-runewidth"
+	"github.com/nsf/termbox-go"
+)
+
+// tuiComments is how many of the last comments the detail pane shows.
+const tuiComments = 5
+
+// tuiMaxResults is how many work items the list shows unless the query sets
+// --maxresults.
+const tuiMaxResults = 500
+
+var tuiHelp = []string{
+	"j/k, arrows    move down/up",
+	"g/G            first/last work item",
+	"ctrl-d/ctrl-u  half a page down/up",
+	"J/K            scroll the details",
+	"enter, l       load the details",
+	"/              filter, esc clears it",
+	"tab            switch between the query and the saved query",
+	"S              pick another saved query",
+	"r              refresh",
+	"s              start working",
+	"R              resolve",
+	"C              close",
+	"m              move to an iteration",
+	"c              add a comment",
+	"o              open in the browser",
+	"?              this help",
+	"q              quit",
+}
+
+type tuiSource struct {
+	Name  string
+	Load  func() ([]*rtc.WorkItem, error)
+	Limit int
+	Hint  string
+}
+
+func savedSource(r *rtc.RTC, name string, itemId string) tuiSource {
+	return tuiSource{
+		Name:  "Saved query: " + name,
+		Load:  func() ([]*rtc.WorkItem, error) { return r.RunSavedQuery(itemId, tuiMaxResults) },
+		Limit: tuiMaxResults,
+		Hint:  "Narrow the saved query to see the others.",
+	}
+}
+
+type tui struct {
+	r       *rtc.RTC
+	sources []tuiSource
+	source  int
+
+	items  []*rtc.WorkItem
+	shown  []*rtc.WorkItem
+	cursor int
+	offset int
+
+	filter    string
+	filtering bool
+
+	details map[string]*rtc.WorkItem
+	scroll  int
+
+	status  string
+	prompt  string
+	input   string
+	onInput func(string)
+	help    bool
+
+	queries []rtc.SavedQuery
+	picking bool
+	pick    int
+}
+
+// runTui browses the work items of the query, or of the saved query.
+func runTui(q Query, saved bool) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if err := q.Check(); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	t := &tui{
+		r: r,
+		sources: []tuiSource{
+			{"Query", func() ([]*rtc.WorkItem, error) { return runQuery(r, q) }, q.MaxResults, "Narrow it or raise --maxresults to see the others."},
+			savedSource(r, "my work items", rtc.MyWorkItemsQuery),
+		},
+		details: map[string]*rtc.WorkItem{},
+	}
+
+	if saved {
+		t.source = 1
+	}
+
+	if err := termbox.Init(); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	termbox.SetInputMode(termbox.InputEsc)
+
+	err = t.loop()
+	termbox.Close()
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
+
+func (t *tui) loop() error {
+	t.load()
+	for {
+		t.draw()
+
+		ev := termbox.PollEvent()
+		switch ev.Type {
+		case termbox.EventError:
+			return ev.Err
+		case termbox.EventKey:
+			if !t.key(ev) {
+				return nil
+			}
+		}
+	}
+}
+
+// busy shows what is going on while the UI waits for the server.
+func (t *tui) busy(msg string) {
+	t.status = msg
+	t.draw()
+}
+
+func (t *tui) load() {
+	src := t.sources[t.source]
+	t.busy("Loading " + src.Name + "...")
+
+	wis, err := src.Load()
+	if err != nil {
+		t.status = err.Error()
+		return
+	}
+
+	t.items = wis
+	t.applyFilter()
+	t.status = fmt.Sprintf("%d work items", len(wis))
+	if src.Limit > 0 && len(wis) >= src.Limit {
+		t.status += ", as many as the query may return. " + src.Hint
+	}
+}
+
+func (t *tui) applyFilter() {
+	words := strings.Fields(strings.ToLower(t.filter))
+
+	t.shown = []*rtc.WorkItem{}
+	for _, wi := range t.items {
+		text := strings.ToLower(strings.Join([]string{wi.Id, wi.Type, wi.Summary, wi.OwnedBy, wi.State, wi.PlannedFor}, " "))
+		matches := true
+		for _, w := range words {
+			if !strings.Contains(text, w) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			t.shown = append(t.shown, wi)
+		}
+	}
+
+	t.move(0)
+}
+
+func (t *tui) current() *rtc.WorkItem {
+	if t.cursor < 0 || t.cursor >= len(t.shown) {
+		return nil
+	}
+
+	return t.shown[t.cursor]
+}
+
+func (t *tui) move(n int) {
+	t.cursor += n
+	if t.cursor >= len(t.shown) {
+		t.cursor = len(t.shown) - 1
+	}
+	if t.cursor < 0 {
+		t.cursor = 0
+	}
+	if n != 0 {
+		t.scroll = 0
+	}
+}
+
+// loadDetails fetches the current work item unless it was already, returning
+// false and leaving the error in the status line when that fails.
+func (t *tui) loadDetails(refresh bool) bool {
+	wi := t.current()
+	if wi == nil {
+		return true
+	}
+	if _, ok := t.details[wi.Id]; ok && !refresh {
+		return true
+	}
+
+	t.busy("Loading " + wi.Id + "...")
+	full, err := t.r.GetWorkItem(wi.Id)
+	if err != nil {
+		t.status = err.Error()
+		return false
+	}
+
+	t.details[wi.Id] = full
+	wi.State = full.State
+	wi.OwnedBy = full.OwnedBy
+	wi.PlannedFor = full.PlannedFor
+	t.status = ""
+	return true
+}
+
+// ask prompts for a line of input and hands it over to fn.
+func (t *tui) ask(prompt string, fn func(string)) {
+	t.prompt = prompt
+	t.input = ""
+	t.onInput = fn
+}
+
+// confirm asks before performing the action on the current work item.
+func (t *tui) confirm(verb string, fn func(wi *rtc.WorkItem)) {
+	wi := t.current()
+	if wi == nil {
+		return
+	}
+
+	t.ask(fmt.Sprintf("%s %s %s? (y/n) ", verb, wi.Type, wi.Id), func(s string) {
+		if strings.HasPrefix(strings.ToLower(s), "y") {
+			fn(wi)
+		}
+	})
+}
+
+func (t *tui) perform(name string, action string) {
+	t.confirm(strings.Title(name), func(wi *rtc.WorkItem) {
+		t.busy(strings.Title(name) + " " + wi.Id + "...")
+		if err := t.r.PerformAction(name, wi.Id, action); err != nil {
+			t.status = err.Error()
+			return
+		}
+
+		if t.loadDetails(true) {
+			t.status = fmt.Sprintf("Work item %s is now %s", wi.Id, wi.State)
+		}
+	})
+}
+
+// key handles a key press, returning false to quit.
+func (t *tui) key(ev termbox.Event) bool {
+	if t.onInput != nil {
+		switch ev.Key {
+		case termbox.KeyEnter:
+			fn, input := t.onInput, t.input
+			t.prompt, t.input, t.onInput = "", "", nil
+			fn(input)
+		case termbox.KeyEsc:
+			t.prompt, t.input, t.onInput = "", "", nil
+		case termbox.KeyBackspace, termbox.KeyBackspace2:
+			t.input = trimLastRune(t.input)
+		case termbox.KeySpace:
+			t.input += " "
+		default:
+			if ev.Ch != 0 {
+				t.input += string(ev.Ch)
+			}
+		}
+		return true
+	}
+
+	if t.filtering {
+		switch ev.Key {
+		case termbox.KeyEnter, termbox.KeyArrowDown, termbox.KeyArrowUp:
+			t.filtering = false
+		case termbox.KeyEsc:
+			t.filtering = false
+			t.filter = ""
+		case termbox.KeyBackspace, termbox.KeyBackspace2:
+			t.filter = trimLastRune(t.filter)
+		case termbox.KeySpace:
+			t.filter += " "
+		default:
+			if ev.Ch != 0 {
+				t.filter += string(ev.Ch)
+			}
+		}
+		t.applyFilter()
+		return true
+	}
+
+	if t.help {
+		t.help = false
+		return true
+	}
+
+	if t.picking {
+		t.pickKey(ev)
+		return true
+	}
+
+	_, h := termbox.Size()
+	page := (h - 2) / 2
+	if page < 1 {
+		page = 1
+	}
+
+	switch ev.Key {
+	case termbox.KeyCtrlC:
+		return false
+	case termbox.KeyArrowDown:
+		t.move(1)
+	case termbox.KeyArrowUp:
+		t.move(-1)
+	case termbox.KeyCtrlD, termbox.KeyPgdn:
+		t.move(page)
+	case termbox.KeyCtrlU, termbox.KeyPgup:
+		t.move(-page)
+	case termbox.KeyHome:
+		t.move(-len(t.shown))
+	case termbox.KeyEnd:
+		t.move(len(t.shown))
+	case termbox.KeyEnter:
+		t.loadDetails(false)
+	case termbox.KeyTab:
+		t.source = (t.source + 1) % len(t.sources)
+		t.cursor, t.offset = 0, 0
+		t.load()
+	case termbox.KeyEsc:
+		t.filter = ""
+		t.applyFilter()
+	}
+
+	switch ev.Ch {
+	case 'q':
+		return false
+	case 'j':
+		t.move(1)
+	case 'k':
+		t.move(-1)
+	case 'g':
+		t.move(-len(t.shown))
+	case 'G':
+		t.move(len(t.shown))
+	case 'J':
+		t.scroll++
+	case 'K':
+		if t.scroll > 0 {
+			t.scroll--
+		}
+	case 'l':
+		t.loadDetails(false)
+	case '/':
+		t.filtering = true
+	case 'r':
+		t.details = map[string]*rtc.WorkItem{}
+		t.load()
+	case '?':
+		t.help = true
+	case 's':
+		t.perform("start", "start working")
+	case 'R':
+		t.perform("resolve", "resolve")
+	case 'C':
+		t.perform("close", "close")
+	case 'm':
+		t.moveToIteration()
+	case 'c':
+		t.addComment()
+	case 'S':
+		t.pickSavedQuery()
+	case 'o':
+		if wi := t.current(); wi != nil {
+			t.busy("Opening " + wi.Id + "...")
+			if err := t.r.OpenWorkItem(wi.Id); err != nil {
+				t.status = err.Error()
+			} else {
+				t.status = ""
+			}
+		}
+	}
+
+	return true
+}
+
+// pickSavedQuery lists the saved queries to pick the one the saved query
+// source runs.
+func (t *tui) pickSavedQuery() {
+	t.busy("Loading the saved queries...")
+	queries, err := t.r.SavedQueries()
+	if err != nil {
+		t.status = err.Error()
+		return
+	}
+	if len(queries) < 1 {
+		t.status = "No saved queries."
+		return
+	}
+
+	t.queries, t.pick, t.picking = queries, 0, true
+	t.status = ""
+}
+
+func (t *tui) pickKey(ev termbox.Event) {
+	switch {
+	case ev.Key == termbox.KeyArrowDown || ev.Ch == 'j':
+		if t.pick < len(t.queries)-1 {
+			t.pick++
+		}
+	case ev.Key == termbox.KeyArrowUp || ev.Ch == 'k':
+		if t.pick > 0 {
+			t.pick--
+		}
+	case ev.Key == termbox.KeyEnter:
+		q := t.queries[t.pick]
+		t.picking = false
+		t.sources[1] = savedSource(t.r, q.Name, q.ItemId)
+		t.source = 1
+		t.cursor, t.offset = 0, 0
+		t.load()
+	case ev.Key == termbox.KeyEsc || ev.Ch == 'q':
+		t.picking = false
+	}
+}
+
+func (t *tui) moveToIteration() {
+	wi := t.current()
+	if wi == nil {
+		return
+	}
+
+	t.ask("Move "+wi.Id+" to iteration (id, label or current/next/previous): ", func(s string) {
+		if strings.TrimSpace(s) == "" {
+			return
+		}
+
+		t.busy("Moving " + wi.Id + "...")
+		_, iter, err := t.r.MoveToIteration(wi.Id, strings.TrimSpace(s))
+		if err != nil {
+			t.status = err.Error()
+			return
+		}
+
+		wi.PlannedFor = iter.Label
+		delete(t.details, wi.Id)
+		t.status = "Moved " + wi.Id + " to " + iter.Label
+	})
+}
+
+func (t *tui) addComment() {
+	wi := t.current()
+	if wi == nil {
+		return
+	}
+
+	t.ask("Comment on "+wi.Id+": ", func(s string) {
+		if strings.TrimSpace(s) == "" {
+			return
+		}
+
+		t.busy("Adding the comment to " + wi.Id + "...")
+		if err := t.r.AddComment(wi.Id, s); err != nil {
+			t.status = err.Error()
+			return
+		}
+
+		if t.loadDetails(true) {
+			t.status = "Comment added to " + wi.Id
+		}
+	})
+}
+
+func trimLastRune(s string) string {
+	r := []rune(s)
+	if len(r) < 1 {
+		return s
+	}
+
+	return string(r[:len(r)-1])
+}
+
+// tprint writes s at x, y without going past maxX, returning where it ended.
+func tprint(x, y, maxX int, fg, bg termbox.Attribute, s string) int {
+	for _, c := range s {
+		w := runewidth.RuneWidth(c)
+		if x+w > maxX {
+			break
+		}
+		termbox.SetCell(x, y, c, fg, bg)
+		x += w
+	}
+
+	return x
+}
+
+// tfill writes s at x, y and pads it with blanks up to maxX.
+func tfill(x, y, maxX int, fg, bg termbox.Attribute, s string) {
+	for x = tprint(x, y, maxX, fg, bg, s); x < maxX; x++ {
+		termbox.SetCell(x, y, ' ', fg, bg)
+	}
+}
+
+// wrap breaks the text into lines of at most width columns.
+func wrap(text string, width int) []string {
+	lines := []string{}
+	for _, para := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			if line != "" && runewidth.StringWidth(line)+1+runewidth.StringWidth(word) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func (t *tui) detailLines(wi *rtc.WorkItem, width int) []string {
+	full, ok := t.details[wi.Id]
+	if !ok {
+		return append(wrap(wi.Type+" "+wi.Id+" - "+wi.Summary, width),
+			"",
+			"         State: "+wi.State,
+			"   Planned For: "+wi.PlannedFor,
+			"         Owner: "+wi.OwnedBy,
+			"",
+			"Press enter to load the details.")
+	}
+	wi = full
+
+	lines := wrap(wi.Type+" "+wi.Id+" - "+wi.Summary, width)
+	lines = append(lines, "",
+		"         State: "+wi.State+" / "+wi.Resolution,
+		"   Planned For: "+wi.PlannedFor,
+		"    Created By: "+wi.CreatedBy,
+		"         Owner: "+wi.OwnedBy)
+	if wi.Estimate != "" {
+		lines = append(lines, "      Estimate: "+wi.Estimate)
+	}
+	if wi.TimeSpent != "" {
+		lines = append(lines, "    Time spent: "+wi.TimeSpent)
+	}
+	if len(wi.Tags) > 0 {
+		lines = append(lines, "          Tags: "+strings.Join(wi.Tags, ", "))
+	}
+
+	for _, p := range wi.Parents {
+		lines = append(lines, "        Parent: "+p.Id+" - "+p.Summary)
+	}
+	for _, c := range wi.Children {
+		lines = append(lines, "         Child: "+c.Id+" - "+c.Summary)
+	}
+
+	lines = append(lines, "", "Description:", "")
+	lines = append(lines, wrap(strings.TrimSpace(sanitize.HTML(wi.Description)), width)...)
+
+	comments := wi.Comments
+	if len(comments) > tuiComments {
+		comments = comments[len(comments)-tuiComments:]
+	}
+	if len(comments) > 0 {
+		lines = append(lines, "", "Comments:")
+	}
+	for _, c := range comments {
+		lines = append(lines, "", c.Author+" - "+c.Created.Local().Format("2006-01-02 15:04"))
+		text := strings.TrimSpace(sanitize.HTML(strings.Replace(c.HTML, "<br/>", "\n", -1)))
+		for _, l := range wrap(text, width-2) {
+			lines = append(lines, "  "+l)
+		}
+	}
+
+	return lines
+}
+
+func (t *tui) draw() {
+	termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
+	w, h := termbox.Size()
+
+	title := fmt.Sprintf(" rtc - %s (%d/%d)", t.sources[t.source].Name, len(t.shown), len(t.items))
+	if t.filter != "" || t.filtering {
+		title += "  filter: " + t.filter
+	}
+	tfill(0, 0, w, termbox.ColorDefault|termbox.AttrBold, termbox.ColorDefault|termbox.AttrReverse, title)
+
+	// the list on the left and the details on the right, or below it when
+	// the terminal is narrow
+	listX, listY, listW, listH := 0, 1, w, h-2
+	detX, detY, detW, detH := 0, 1, 0, 0
+	if w >= 100 {
+		listW = w * 45 / 100
+		detX, detW, detH = listW+1, w-listW-1, h-2
+		for y := 1; y < h-1; y++ {
+			termbox.SetCell(listW, y, '|', termbox.ColorDefault, termbox.ColorDefault)
+		}
+	} else {
+		listH = (h - 2) / 2
+		detY, detW, detH = listY+listH+1, w, h-3-listH
+		tfill(0, listY+listH, w, termbox.ColorDefault, termbox.ColorDefault, strings.Repeat("-", w))
+	}
+
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if listH > 0 && t.cursor >= t.offset+listH {
+		t.offset = t.cursor - listH + 1
+	}
+
+	for n := 0; n < listH && t.offset+n < len(t.shown); n++ {
+		wi := t.shown[t.offset+n]
+		fg, bg := termbox.ColorDefault, termbox.ColorDefault
+		if t.offset+n == t.cursor {
+			bg |= termbox.AttrReverse
+		}
+		row := fmt.Sprintf("%-6s %-8.8s %-12.12s %s", wi.Id, wi.Type, wi.State, wi.Summary)
+		tfill(listX, listY+n, listX+listW, fg, bg, row)
+	}
+	if len(t.shown) < 1 {
+		tprint(listX+1, listY, listX+listW, termbox.ColorDefault, termbox.ColorDefault, "No work items.")
+	}
+
+	if wi := t.current(); wi != nil && detW > 2 {
+		lines := t.detailLines(wi, detW-2)
+		if t.scroll > len(lines)-1 {
+			t.scroll = len(lines) - 1
+		}
+		for n := 0; n < detH && t.scroll+n < len(lines); n++ {
+			tprint(detX+1, detY+n, detX+detW, termbox.ColorDefault, termbox.ColorDefault, lines[t.scroll+n])
+		}
+	}
+
+	if t.help {
+		t.drawHelp(w, h)
+	}
+	if t.picking {
+		t.drawPicker(w, h)
+	}
+
+	termbox.HideCursor()
+	switch {
+	case t.onInput != nil:
+		x := tprint(0, h-1, w, termbox.ColorYellow, termbox.ColorDefault, t.prompt)
+		x = tprint(x, h-1, w, termbox.ColorDefault, termbox.ColorDefault, t.input)
+		termbox.SetCursor(x, h-1)
+	case t.filtering:
+		x := tprint(0, h-1, w, termbox.ColorYellow, termbox.ColorDefault, "/")
+		x = tprint(x, h-1, w, termbox.ColorDefault, termbox.ColorDefault, t.filter)
+		termbox.SetCursor(x, h-1)
+	case t.status != "":
+		tprint(0, h-1, w, termbox.ColorDefault, termbox.ColorDefault, t.status)
+	default:
+		tprint(0, h-1, w, termbox.ColorCyan, termbox.ColorDefault, "enter details  / filter  s start  R resolve  C close  m move  c comment  o open  ? help  q quit")
+	}
+
+	termbox.Flush()
+}
+
+func (t *tui) drawHelp(w, h int) {
+	width := 0
+	for _, l := range tuiHelp {
+		if lw := runewidth.StringWidth(l); lw > width {
+			width = lw
+		}
+	}
+	width += 4
+
+	x, y := (w-width)/2, (h-len(tuiHelp)-2)/2
+	if x < 0 {
+		x = 0
+	}
+	if y < 1 {
+		y = 1
+	}
+
+	tfill(x, y, x+width, termbox.ColorDefault, termbox.ColorDefault|termbox.AttrReverse, " Keys")
+	for n, l := range tuiHelp {
+		tfill(x, y+n+1, x+width, termbox.ColorDefault, termbox.ColorDefault|termbox.AttrReverse, "  "+l)
+	}
+	tfill(x, y+len(tuiHelp)+1, x+width, termbox.ColorDefault, termbox.ColorDefault|termbox.AttrReverse, "")
+}
+
+func (t *tui) drawPicker(w, h int) {
+	title := " Saved queries (enter picks, esc cancels)"
+	width := runewidth.StringWidth(title)
+	for _, q := range t.queries {
+		if qw := runewidth.StringWidth(q.Name); qw > width {
+			width = qw
+		}
+	}
+	width += 4
+	if width > w {
+		width = w
+	}
+
+	rows := len(t.queries)
+	if rows > h-4 {
+		rows = h - 4
+	}
+	first := 0
+	if t.pick >= rows {
+		first = t.pick - rows + 1
+	}
+
+	x, y := (w-width)/2, (h-rows-2)/2
+	if x < 0 {
+		x = 0
+	}
+	if y < 1 {
+		y = 1
+	}
+
+	bg := termbox.ColorDefault | termbox.AttrReverse
+	tfill(x, y, x+width, termbox.ColorDefault, bg, title)
+	for n := 0; n < rows; n++ {
+		mark := "  "
+		if first+n == t.pick {
+			mark = "> "
+		}
+		tfill(x, y+n+1, x+width, termbox.ColorDefault, bg, mark+t.queries[first+n].Name)
+	}
+	tfill(x, y+rows+1, x+width, termbox.ColorDefault, bg, "")
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fcoury/rtc-go/rtc"
+	"github.com/gistia/tablewriter"
+)
+
+func capacities() (map[string]time.Duration, error) {
+	capacity := map[string]time.Duration{}
+	for owner, s := range appConfig.Capacity {
+		d, err := rtc.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid capacity %q for %s: %s", s, owner, err.Error())
+		}
+		capacity[owner] = d
+	}
+
+	return capacity, nil
+}
+
+func workload(iterId string) {
+	r, err := login()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	capacity, err := capacities()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	w, err := r.GetWorkload(iterId, capacity)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	for _, key := range w.UnknownOwners {
+		fmt.Printf("Warning: no owner matches %s in the capacity of your config, skipped.\n", key)
+	}
+
+	iter := w.Iteration
+	fmt.Printf("\n%s  [%s, %s]\n\n", iter.Label, dateRange(iter.Start(), iter.End()), daysLeft(iter.Start(), iter.End(), time.Now()))
+
+	if len(w.Owners) < 1 {
+		fmt.Println("No open work items.")
+		return
+	}
+
+	over := 0
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"", "Owner", "Items", "Estimate", "Capacity", "Load"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetColWidth(appConfig.MaxWidth)
+	for _, o := range w.Owners {
+		mark, load := "", "-"
+		if o.Capacity > 0 {
+			load = strconv.Itoa(int(o.Load()*100+0.5)) + "%"
+		}
+		if o.Overallocated() {
+			mark = "!"
+			load += " OVER"
+			over++
+		}
+		estimate := formatHours(o.Estimate)
+		if len(o.Unparsed) > 0 {
+			estimate += " + ?"
+		}
+		table.Append([]string{mark, valueOrNone(o.Owner.Name), strconv.Itoa(o.Items), estimate, formatHours(o.Capacity), load})
+	}
+	table.Render()
+
+	if over > 0 {
+		fmt.Printf("\n%d overallocated.\n", over)
+	}
+
+	unparsed := []string{}
+	for _, o := range w.Owners {
+		for _, wi := range o.Unparsed {
+			unparsed = append(unparsed, fmt.Sprintf("  %s (%s): %q", wi.Id, valueOrNone(o.Owner.Name), wi.Estimate))
+		}
+	}
+	if len(unparsed) > 0 {
+		fmt.Printf("\nThe estimates of %d work item(s) couldn't be read and aren't counted:\n%s\n", len(unparsed), strings.Join(unparsed, "\n"))
+	}
+	if len(appConfig.Capacity) < 1 {
+		fmt.Println("\nSet each owner's capacity per iteration under \"capacity\" in the config to compare, e.g. {\"*\": \"60h\"}.")
+	}
+}